@@ -0,0 +1,376 @@
+package check
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+)
+
+type (
+	jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		Use string `json:"use"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+)
+
+// Ed25519 returns an ed25519 signature checker
+func Ed25519(pub ed25519.PublicKey) Signature {
+	return func(p, s []byte) error {
+		if len(p) == 0 {
+			return fmt.Errorf("payload cannot be empty")
+		}
+
+		if len(s) == 0 {
+			return fmt.Errorf("signature cannot be empty")
+		}
+
+		if len(pub) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid ed25519 public key")
+		}
+
+		if !ed25519.Verify(pub, p, s) {
+			return fmt.Errorf("invalid signature")
+		}
+
+		return nil
+	}
+}
+
+// ECDSAP256 returns an ecdsa P-256/sha256 signature checker, signatures are expected in their
+// fixed size r||s form, the same one used by JWS (ES256)
+func ECDSAP256(pub *ecdsa.PublicKey) Signature {
+	return func(p, s []byte) error {
+		if len(p) == 0 {
+			return fmt.Errorf("payload cannot be empty")
+		}
+
+		if len(s) == 0 {
+			return fmt.Errorf("signature cannot be empty")
+		}
+
+		if pub == nil || pub.Curve != elliptic.P256() {
+			return fmt.Errorf("invalid ecdsa P-256 public key")
+		}
+
+		if len(s) != 64 {
+			return fmt.Errorf("invalid signature")
+		}
+
+		r := new(big.Int).SetBytes(s[:32])
+		ss := new(big.Int).SetBytes(s[32:])
+		digest := sha256.Sum256(p)
+		if !ecdsa.Verify(pub, digest[:], r, ss) {
+			return fmt.Errorf("invalid signature")
+		}
+
+		return nil
+	}
+}
+
+// RSAPSS returns a rsa-pss/sha256 signature checker
+func RSAPSS(pub *rsa.PublicKey) Signature {
+	return func(p, s []byte) error {
+		if len(p) == 0 {
+			return fmt.Errorf("payload cannot be empty")
+		}
+
+		if len(s) == 0 {
+			return fmt.Errorf("signature cannot be empty")
+		}
+
+		if pub == nil {
+			return fmt.Errorf("invalid rsa public key")
+		}
+
+		digest := sha256.Sum256(p)
+		err := rsa.VerifyPSS(pub, crypto.SHA256, digest[:], s, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		if err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+
+		return nil
+	}
+}
+
+// PublicKey returns the signature checker matching the type of the given public key
+func PublicKey(pub crypto.PublicKey) (Signature, error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		return Ed25519(k), nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ecdsa curve %s", k.Curve.Params().Name)
+		}
+		return ECDSAP256(k), nil
+	case *rsa.PublicKey:
+		return RSAPSS(k), nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// Ed25519Signer returns an ed25519 signer, the counterpart of Ed25519
+func Ed25519Signer(priv ed25519.PrivateKey) Sign {
+	return func(p []byte) ([]byte, error) {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("payload cannot be empty")
+		}
+
+		if len(priv) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("invalid ed25519 private key")
+		}
+
+		return ed25519.Sign(priv, p), nil
+	}
+}
+
+// ECDSAP256Signer returns an ecdsa P-256/sha256 signer producing fixed size r||s signatures, the counterpart of ECDSAP256
+func ECDSAP256Signer(priv *ecdsa.PrivateKey) Sign {
+	return func(p []byte) ([]byte, error) {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("payload cannot be empty")
+		}
+
+		if priv == nil || priv.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("invalid ecdsa P-256 private key")
+		}
+
+		digest := sha256.Sum256(p)
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			return nil, fmt.Errorf("could not sign payload: %w", err)
+		}
+
+		sig := make([]byte, 64)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(sig[32-len(rb):32], rb)
+		copy(sig[64-len(sb):], sb)
+		return sig, nil
+	}
+}
+
+// RSAPSSSigner returns a rsa-pss/sha256 signer, the counterpart of RSAPSS
+func RSAPSSSigner(priv *rsa.PrivateKey) Sign {
+	return func(p []byte) ([]byte, error) {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("payload cannot be empty")
+		}
+
+		if priv == nil {
+			return nil, fmt.Errorf("invalid rsa private key")
+		}
+
+		digest := sha256.Sum256(p)
+		s, err := rsa.SignPSS(rand.Reader, priv, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		if err != nil {
+			return nil, fmt.Errorf("could not sign payload: %w", err)
+		}
+
+		return s, nil
+	}
+}
+
+// PrivateKey returns the signer matching the type of the given private key
+func PrivateKey(priv crypto.PrivateKey) (Sign, error) {
+	switch k := priv.(type) {
+	case ed25519.PrivateKey:
+		return Ed25519Signer(k), nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ecdsa curve %s", k.Curve.Params().Name)
+		}
+		return ECDSAP256Signer(k), nil
+	case *rsa.PrivateKey:
+		return RSAPSSSigner(k), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", priv)
+	}
+}
+
+// ParsePrivatePEM returns the signer for a PEM encoded PKCS#8, PKCS#1 (RSA) or SEC 1 (EC) private key
+func ParsePrivatePEM(b []byte) (Sign, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("could not decode pem: no pem block found")
+	}
+
+	var (
+		priv crypto.PrivateKey
+		err  error
+	)
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		priv, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %w", err)
+	}
+
+	return PrivateKey(priv)
+}
+
+// LoadPrivatePEM returns the signer for the PEM encoded private key stored in the given file, see ParsePrivatePEM
+func LoadPrivatePEM(path string) (Sign, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read private key: %w", err)
+	}
+
+	return ParsePrivatePEM(b)
+}
+
+// ParsePEM returns the signature checker for a PEM encoded PKIX public key
+func ParsePEM(b []byte) (Signature, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("could not decode pem: no pem block found")
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse public key: %w", err)
+	}
+
+	return PublicKey(pub)
+}
+
+// LoadPEM returns the signature checker for the PEM encoded PKIX public key stored in the given file
+func LoadPEM(path string) (Signature, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read public key: %w", err)
+	}
+
+	return ParsePEM(b)
+}
+
+// ParseJWKS returns a keyring holding the public keys found in a JWKS document, keys are identified by their kid
+func ParseJWKS(b []byte, p Policy) (*Keyring, error) {
+	var set jwks
+	err := json.Unmarshal(b, &set)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal jwks: %w", err)
+	}
+
+	k := NewKeyring(p)
+	for i, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		if key.Kid == "" {
+			return nil, fmt.Errorf("could not parse key %d: missing kid", i)
+		}
+
+		pub, err := key.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("could not parse key %s: %w", key.Kid, err)
+		}
+
+		sc, err := PublicKey(pub)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse key %s: %w", key.Kid, err)
+		}
+
+		k.Add(Key{ID: key.Kid, Checker: sc})
+	}
+
+	return k, nil
+}
+
+// LoadJWKS returns a keyring holding the public keys found in the JWKS document stored in the given file
+func LoadJWKS(path string, p Policy) (*Keyring, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read jwks: %w", err)
+	}
+
+	return ParseJWKS(b, p)
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "OKP":
+		if k.Crv != "Ed25519" || (k.Alg != "" && k.Alg != "EdDSA") {
+			return nil, fmt.Errorf("unsupported OKP key %s/%s", k.Crv, k.Alg)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x: %w", err)
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 public key size %d", len(x))
+		}
+
+		return ed25519.PublicKey(x), nil
+	case "EC":
+		if k.Crv != "P-256" || (k.Alg != "" && k.Alg != "ES256") {
+			return nil, fmt.Errorf("unsupported EC key %s/%s", k.Crv, k.Alg)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x: %w", err)
+		}
+
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y: %w", err)
+		}
+
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("point is not on the P-256 curve")
+		}
+
+		return pub, nil
+	case "RSA":
+		if k.Alg != "" && k.Alg != "PS256" {
+			return nil, fmt.Errorf("unsupported RSA algorithm %s", k.Alg)
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid n: %w", err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid e: %w", err)
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
@@ -0,0 +1,316 @@
+package check_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/at-silva/ddapi/check"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Asymmetric signatures", func() {
+
+	var (
+		q         []byte
+		edPub     ed25519.PublicKey
+		edPriv    ed25519.PrivateKey
+		ecPriv    *ecdsa.PrivateKey
+		rsaPriv   *rsa.PrivateKey
+		edSign    func(p []byte) []byte
+		ecSign    func(p []byte) []byte
+		rsaSign   func(p []byte) []byte
+		b64       func(b []byte) string
+		dir       string
+		writePKIX func(name string, pub crypto.PublicKey) string
+		err       error
+	)
+
+	BeforeEach(func() {
+		q = []byte("select name, id from users where id = :id")
+
+		edPub, edPriv, err = ed25519.GenerateKey(rand.Reader)
+		Expect(err).ShouldNot(HaveOccurred())
+		ecPriv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ShouldNot(HaveOccurred())
+		rsaPriv, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		edSign = func(p []byte) []byte {
+			return ed25519.Sign(edPriv, p)
+		}
+
+		ecSign = func(p []byte) []byte {
+			d := sha256.Sum256(p)
+			r, s, err := ecdsa.Sign(rand.Reader, ecPriv, d[:])
+			Expect(err).ShouldNot(HaveOccurred())
+			return append(pad32(r), pad32(s)...)
+		}
+
+		rsaSign = func(p []byte) []byte {
+			d := sha256.Sum256(p)
+			s, err := rsa.SignPSS(rand.Reader, rsaPriv, crypto.SHA256, d[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+			Expect(err).ShouldNot(HaveOccurred())
+			return s
+		}
+
+		b64 = func(b []byte) string {
+			return base64.RawURLEncoding.EncodeToString(b)
+		}
+
+		dir, err = ioutil.TempDir("", "ddapi-check")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		writePKIX = func(name string, pub crypto.PublicKey) string {
+			der, err := x509.MarshalPKIXPublicKey(pub)
+			Expect(err).ShouldNot(HaveOccurred())
+			path := filepath.Join(dir, name)
+			Expect(ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600)).Should(Succeed())
+			return path
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).Should(Succeed())
+	})
+
+	Describe("Ed25519", func() {
+		It("should accept a valid signature", func() {
+			Expect(check.Ed25519(edPub).Check(q, edSign(q))).Should(Succeed())
+		})
+
+		It("should fail if the signature is invalid", func() {
+			Expect(check.Ed25519(edPub).Check(q, edSign([]byte("another query")))).Should(MatchError("invalid signature"))
+		})
+
+		It("should fail if the payload or signature are empty", func() {
+			Expect(check.Ed25519(edPub).Check([]byte{}, edSign(q))).ShouldNot(Succeed())
+			Expect(check.Ed25519(edPub).Check(q, []byte{})).ShouldNot(Succeed())
+		})
+	})
+
+	Describe("ECDSAP256", func() {
+		It("should accept a valid signature", func() {
+			Expect(check.ECDSAP256(&ecPriv.PublicKey).Check(q, ecSign(q))).Should(Succeed())
+		})
+
+		It("should fail if the signature is invalid", func() {
+			Expect(check.ECDSAP256(&ecPriv.PublicKey).Check(q, ecSign([]byte("another query")))).Should(MatchError("invalid signature"))
+		})
+
+		It("should fail if the signature is not in its r||s form", func() {
+			Expect(check.ECDSAP256(&ecPriv.PublicKey).Check(q, ecSign(q)[:63])).Should(MatchError("invalid signature"))
+		})
+
+		It("should fail if the key is not a P-256 key", func() {
+			k, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(check.ECDSAP256(&k.PublicKey).Check(q, ecSign(q))).Should(MatchError("invalid ecdsa P-256 public key"))
+		})
+	})
+
+	Describe("RSAPSS", func() {
+		It("should accept a valid signature", func() {
+			Expect(check.RSAPSS(&rsaPriv.PublicKey).Check(q, rsaSign(q))).Should(Succeed())
+		})
+
+		It("should fail if the signature is invalid", func() {
+			Expect(check.RSAPSS(&rsaPriv.PublicKey).Check(q, rsaSign([]byte("another query")))).Should(MatchError("invalid signature"))
+		})
+
+		It("should fail if the signature is a PKCS#1 v1.5 signature", func() {
+			d := sha256.Sum256(q)
+			s, err := rsa.SignPKCS1v15(rand.Reader, rsaPriv, crypto.SHA256, d[:])
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(check.RSAPSS(&rsaPriv.PublicKey).Check(q, s)).Should(MatchError("invalid signature"))
+		})
+	})
+
+	Describe("Signers", func() {
+		It("should produce signatures accepted by their checkers", func() {
+			s, err := check.Ed25519Signer(edPriv).Sign(q)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(check.Ed25519(edPub).Check(q, s)).Should(Succeed())
+
+			s, err = check.ECDSAP256Signer(ecPriv).Sign(q)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(s).Should(HaveLen(64))
+			Expect(check.ECDSAP256(&ecPriv.PublicKey).Check(q, s)).Should(Succeed())
+
+			s, err = check.RSAPSSSigner(rsaPriv).Sign(q)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(check.RSAPSS(&rsaPriv.PublicKey).Check(q, s)).Should(Succeed())
+		})
+
+		It("should fail if the payload is empty", func() {
+			_, err := check.Ed25519Signer(edPriv).Sign([]byte{})
+			Expect(err).Should(MatchError("payload cannot be empty"))
+		})
+
+		It("should fail if the ecdsa curve is not supported", func() {
+			k, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = check.PrivateKey(k)
+			Expect(err).Should(MatchError("unsupported ecdsa curve P-384"))
+		})
+	})
+
+	Describe("LoadPrivatePEM", func() {
+
+		var writeKey func(name, typ string, der []byte) string
+
+		BeforeEach(func() {
+			writeKey = func(name, typ string, der []byte) string {
+				path := filepath.Join(dir, name)
+				Expect(ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600)).Should(Succeed())
+				return path
+			}
+		})
+
+		It("should load PKCS#8, PKCS#1 and SEC 1 private keys", func() {
+			pkcs8 := func(priv crypto.PrivateKey) []byte {
+				der, err := x509.MarshalPKCS8PrivateKey(priv)
+				Expect(err).ShouldNot(HaveOccurred())
+				return der
+			}
+
+			sec1, err := x509.MarshalECPrivateKey(ecPriv)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			keys := map[string]check.Signature{
+				writeKey("ed25519.pem", "PRIVATE KEY", pkcs8(edPriv)):                              check.Ed25519(edPub),
+				writeKey("ecdsa.pem", "PRIVATE KEY", pkcs8(ecPriv)):                                check.ECDSAP256(&ecPriv.PublicKey),
+				writeKey("ecdsa-sec1.pem", "EC PRIVATE KEY", sec1):                                 check.ECDSAP256(&ecPriv.PublicKey),
+				writeKey("rsa.pem", "PRIVATE KEY", pkcs8(rsaPriv)):                                 check.RSAPSS(&rsaPriv.PublicKey),
+				writeKey("rsa-pkcs1.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaPriv)): check.RSAPSS(&rsaPriv.PublicKey),
+			}
+
+			for path, sc := range keys {
+				signer, err := check.LoadPrivatePEM(path)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				s, err := signer.Sign(q)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(sc.Check(q, s)).Should(Succeed(), path)
+			}
+		})
+
+		It("should fail if the file does not exist", func() {
+			_, err := check.LoadPrivatePEM(filepath.Join(dir, "missing.pem"))
+			Expect(err).Should(MatchError(ContainSubstring("could not read private key")))
+		})
+
+		It("should fail if the file does not hold a private key", func() {
+			_, err := check.LoadPrivatePEM(writePKIX("public.pem", edPub))
+			Expect(err).Should(MatchError(ContainSubstring("could not parse private key")))
+		})
+	})
+
+	Describe("LoadPEM", func() {
+		It("should load ed25519, ecdsa and rsa public keys", func() {
+			sc, err := check.LoadPEM(writePKIX("ed25519.pem", edPub))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(sc.Check(q, edSign(q))).Should(Succeed())
+
+			sc, err = check.LoadPEM(writePKIX("ecdsa.pem", &ecPriv.PublicKey))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(sc.Check(q, ecSign(q))).Should(Succeed())
+
+			sc, err = check.LoadPEM(writePKIX("rsa.pem", &rsaPriv.PublicKey))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(sc.Check(q, rsaSign(q))).Should(Succeed())
+		})
+
+		It("should fail if the file does not exist", func() {
+			_, err := check.LoadPEM(filepath.Join(dir, "missing.pem"))
+			Expect(err).Should(MatchError(ContainSubstring("could not read public key")))
+		})
+
+		It("should fail if the file is not PEM encoded", func() {
+			path := filepath.Join(dir, "invalid.pem")
+			Expect(ioutil.WriteFile(path, []byte("not a pem"), 0600)).Should(Succeed())
+			_, err := check.LoadPEM(path)
+			Expect(err).Should(MatchError("could not decode pem: no pem block found"))
+		})
+
+		It("should fail if the ecdsa curve is not supported", func() {
+			k, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = check.LoadPEM(writePKIX("p384.pem", &k.PublicKey))
+			Expect(err).Should(MatchError("unsupported ecdsa curve P-384"))
+		})
+	})
+
+	Describe("LoadJWKS", func() {
+
+		var path string
+
+		BeforeEach(func() {
+			path = filepath.Join(dir, "jwks.json")
+			doc := fmt.Sprintf(`{"keys": [
+				{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "alg": "EdDSA", "x": "%s"},
+				{"kty": "EC", "kid": "ec", "crv": "P-256", "alg": "ES256", "x": "%s", "y": "%s"},
+				{"kty": "RSA", "kid": "rsa", "alg": "PS256", "n": "%s", "e": "%s"},
+				{"kty": "RSA", "kid": "enc", "use": "enc", "n": "%s", "e": "%s"}
+			]}`,
+				b64(edPub),
+				b64(pad32(ecPriv.PublicKey.X)), b64(pad32(ecPriv.PublicKey.Y)),
+				b64(rsaPriv.PublicKey.N.Bytes()), b64(big.NewInt(int64(rsaPriv.PublicKey.E)).Bytes()),
+				b64(rsaPriv.PublicKey.N.Bytes()), b64(big.NewInt(int64(rsaPriv.PublicKey.E)).Bytes()),
+			)
+			Expect(ioutil.WriteFile(path, []byte(doc), 0600)).Should(Succeed())
+		})
+
+		It("should load every signing key into a keyring", func() {
+			k, err := check.LoadJWKS(path, check.Policy{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(k.Uses()).Should(HaveLen(3))
+
+			for kid, sign := range map[string]func(p []byte) []byte{"ed": edSign, "ec": ecSign, "rsa": rsaSign} {
+				s, err := check.Stamp(check.Header{KeyID: kid}, q, check.Sign(func(p []byte) ([]byte, error) { return sign(p), nil }))
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(k.Check(q, s)).Should(Succeed())
+			}
+		})
+
+		It("should fail if a key has no kid", func() {
+			_, err := check.ParseJWKS([]byte(`{"keys": [{"kty": "OKP", "crv": "Ed25519", "x": "`+b64(edPub)+`"}]}`), check.Policy{})
+			Expect(err).Should(MatchError("could not parse key 0: missing kid"))
+		})
+
+		It("should fail if a key type is not supported", func() {
+			_, err := check.ParseJWKS([]byte(`{"keys": [{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"}]}`), check.Policy{})
+			Expect(err).Should(MatchError("could not parse key hmac: unsupported key type oct"))
+		})
+
+		It("should fail if an ec point is not on the curve", func() {
+			_, err := check.ParseJWKS([]byte(`{"keys": [{"kty": "EC", "kid": "ec", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`), check.Policy{})
+			Expect(err).Should(MatchError("could not parse key ec: point is not on the P-256 curve"))
+		})
+
+		It("should fail if the file does not exist", func() {
+			_, err := check.LoadJWKS(filepath.Join(dir, "missing.json"), check.Policy{})
+			Expect(err).Should(MatchError(ContainSubstring("could not read jwks")))
+		})
+	})
+
+})
+
+func pad32(i *big.Int) []byte {
+	b := i.Bytes()
+	return append(make([]byte, 32-len(b)), b...)
+}
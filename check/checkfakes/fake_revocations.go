@@ -0,0 +1,111 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package checkfakes
+
+import (
+	"sync"
+
+	"github.com/at-silva/ddapi/check"
+)
+
+type FakeRevocations struct {
+	RevokedStub        func(string) bool
+	revokedMutex       sync.RWMutex
+	revokedArgsForCall []struct {
+		arg1 string
+	}
+	revokedReturns struct {
+		result1 bool
+	}
+	revokedReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRevocations) Revoked(arg1 string) bool {
+	fake.revokedMutex.Lock()
+	ret, specificReturn := fake.revokedReturnsOnCall[len(fake.revokedArgsForCall)]
+	fake.revokedArgsForCall = append(fake.revokedArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RevokedStub
+	fakeReturns := fake.revokedReturns
+	fake.recordInvocation("Revoked", []interface{}{arg1})
+	fake.revokedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRevocations) RevokedCallCount() int {
+	fake.revokedMutex.RLock()
+	defer fake.revokedMutex.RUnlock()
+	return len(fake.revokedArgsForCall)
+}
+
+func (fake *FakeRevocations) RevokedCalls(stub func(string) bool) {
+	fake.revokedMutex.Lock()
+	defer fake.revokedMutex.Unlock()
+	fake.RevokedStub = stub
+}
+
+func (fake *FakeRevocations) RevokedArgsForCall(i int) string {
+	fake.revokedMutex.RLock()
+	defer fake.revokedMutex.RUnlock()
+	argsForCall := fake.revokedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeRevocations) RevokedReturns(result1 bool) {
+	fake.revokedMutex.Lock()
+	defer fake.revokedMutex.Unlock()
+	fake.RevokedStub = nil
+	fake.revokedReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeRevocations) RevokedReturnsOnCall(i int, result1 bool) {
+	fake.revokedMutex.Lock()
+	defer fake.revokedMutex.Unlock()
+	fake.RevokedStub = nil
+	if fake.revokedReturnsOnCall == nil {
+		fake.revokedReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.revokedReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeRevocations) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.revokedMutex.RLock()
+	defer fake.revokedMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRevocations) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ check.Revocations = new(FakeRevocations)
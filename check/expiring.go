@@ -0,0 +1,146 @@
+package check
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// Policy rules applied to the header of stamped signatures
+	Policy struct {
+		// MaxAge rejects signatures issued more than MaxAge ago, zero accepts signatures of any age
+		MaxAge time.Duration
+		// Leeway tolerance applied to the time based checks to account for clock skew
+		Leeway time.Duration
+		// MinVersions minimum version accepted for each statement id
+		MinVersions map[string]int
+		// Revoked revoked statement ids and payload digests
+		Revoked Revocations
+		// Now returns the current time, defaults to time.Now
+		Now func() time.Time
+	}
+
+	// Revocations represents a server-side revocation list
+	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Revocations
+	Revocations interface {
+		Revoked(id string) bool
+	}
+
+	// RevocationList an in-memory revocation list, safe for concurrent use
+	RevocationList struct {
+		mu  sync.RWMutex
+		ids map[string]struct{}
+	}
+)
+
+// Expiring returns a checker for stamped signatures (see Stamp), the header and payload are checked with sc
+// and the header is then validated against the given policy
+func Expiring(sc SignatureChecker, p Policy) Signature {
+	return func(payload, s []byte) error {
+		if len(payload) == 0 {
+			return fmt.Errorf("payload cannot be empty")
+		}
+
+		h, signed, raw, err := Unstamp(payload, s)
+		if err != nil {
+			return err
+		}
+
+		err = sc.Check(signed, raw)
+		if err != nil {
+			return err
+		}
+
+		return p.Validate(h, payload)
+	}
+}
+
+// Validate validates a signature header against the policy
+func (p Policy) Validate(h Header, payload []byte) error {
+	t := p.now()
+
+	if h.IssuedAt != 0 && time.Unix(h.IssuedAt, 0).After(t.Add(p.Leeway)) {
+		return fmt.Errorf("signature issued in the future")
+	}
+
+	if h.NotBefore != 0 && time.Unix(h.NotBefore, 0).After(t.Add(p.Leeway)) {
+		return fmt.Errorf("signature not valid yet")
+	}
+
+	if h.ExpiresAt != 0 && !time.Unix(h.ExpiresAt, 0).After(t.Add(-p.Leeway)) {
+		return fmt.Errorf("signature expired")
+	}
+
+	if p.MaxAge != 0 {
+		if h.IssuedAt == 0 {
+			return fmt.Errorf("signature issue date is missing")
+		}
+
+		if time.Unix(h.IssuedAt, 0).Add(p.MaxAge + p.Leeway).Before(t) {
+			return fmt.Errorf("signature too old")
+		}
+	}
+
+	if min, ok := p.MinVersions[h.ID]; ok && h.Version < min {
+		return fmt.Errorf("statement version %d is below the minimum version %d", h.Version, min)
+	}
+
+	if p.Revoked != nil {
+		if h.ID != "" && p.Revoked.Revoked(h.ID) {
+			return fmt.Errorf("statement %s revoked", h.ID)
+		}
+
+		if p.Revoked.Revoked(Digest(payload)) {
+			return fmt.Errorf("statement revoked")
+		}
+	}
+
+	return nil
+}
+
+func (p Policy) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+
+	return time.Now()
+}
+
+// Digest returns the digest identifying a payload in a revocation list
+func Digest(payload []byte) string {
+	d := sha256.Sum256(payload)
+	return "sha256:" + hex.EncodeToString(d[:])
+}
+
+// NewRevocationList returns a revocation list containing the given statement ids and payload digests
+func NewRevocationList(ids ...string) *RevocationList {
+	l := &RevocationList{}
+	l.Revoke(ids...)
+	return l
+}
+
+// Revoke adds the given statement ids and payload digests to the list
+func (l *RevocationList) Revoke(ids ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.ids == nil {
+		l.ids = map[string]struct{}{}
+	}
+
+	for _, id := range ids {
+		l.ids[id] = struct{}{}
+	}
+}
+
+// Revoked checks if a statement id or payload digest has been revoked
+func (l *RevocationList) Revoked(id string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, ok := l.ids[id]
+	return ok
+}
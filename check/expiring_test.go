@@ -0,0 +1,128 @@
+package check_test
+
+import (
+	"time"
+
+	"github.com/at-silva/ddapi/check"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Expiring", func() {
+
+	var (
+		key     []byte
+		now     time.Time
+		policy  check.Policy
+		h       check.Header
+		p       []byte
+		stamp   func() []byte
+		checker func() check.SignatureChecker
+	)
+
+	BeforeEach(func() {
+		key = []byte("my secret key")
+		now = time.Unix(1600000000, 0)
+		policy = check.Policy{Now: func() time.Time { return now }}
+		h = check.Header{ID: "products.list", Version: 1, IssuedAt: now.Add(-time.Hour).Unix()}
+		p = []byte("select name, id from product where id = :id")
+
+		stamp = func() []byte {
+			s, err := check.Stamp(h, p, check.Sha256HMACSigner(key))
+			Expect(err).ShouldNot(HaveOccurred())
+			return s
+		}
+
+		checker = func() check.SignatureChecker {
+			return check.Expiring(check.Sha256HMAC(key), policy)
+		}
+	})
+
+	It("should accept a valid stamped signature", func() {
+		Expect(checker().Check(p, stamp())).Should(Succeed())
+	})
+
+	It("should fail if the payload is empty", func() {
+		Expect(checker().Check([]byte{}, stamp())).ShouldNot(Succeed())
+	})
+
+	It("should fail if the signature is not stamped", func() {
+		s, err := check.Sha256HMACSigner(key)(p)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(checker().Check(p, s)).ShouldNot(Succeed())
+	})
+
+	It("should fail if the header was tampered with", func() {
+		s := stamp()
+		h.Version = 2
+		tampered, err := check.Stamp(h, p, check.Sha256HMACSigner([]byte("another key")))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, _, raw, err := check.Unstamp(p, s)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, _, traw, err := check.Unstamp(p, tampered)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		forged := append(tampered[:len(tampered)-len(traw)], raw...)
+		Expect(checker().Check(p, forged)).Should(MatchError("invalid signature"))
+	})
+
+	It("should fail if the signature expired", func() {
+		h.ExpiresAt = now.Add(-time.Minute).Unix()
+		Expect(checker().Check(p, stamp())).Should(MatchError("signature expired"))
+	})
+
+	It("should accept an expired signature within the leeway", func() {
+		h.ExpiresAt = now.Add(-time.Minute).Unix()
+		policy.Leeway = 2 * time.Minute
+		Expect(checker().Check(p, stamp())).Should(Succeed())
+	})
+
+	It("should fail if the signature is not valid yet", func() {
+		h.NotBefore = now.Add(time.Minute).Unix()
+		Expect(checker().Check(p, stamp())).Should(MatchError("signature not valid yet"))
+	})
+
+	It("should fail if the signature was issued in the future", func() {
+		h.IssuedAt = now.Add(time.Minute).Unix()
+		Expect(checker().Check(p, stamp())).Should(MatchError("signature issued in the future"))
+	})
+
+	It("should fail if the signature is older than the max age", func() {
+		policy.MaxAge = 30 * time.Minute
+		Expect(checker().Check(p, stamp())).Should(MatchError("signature too old"))
+	})
+
+	It("should fail if the max age is set and the signature has no issue date", func() {
+		policy.MaxAge = 30 * time.Minute
+		h.IssuedAt = 0
+		Expect(checker().Check(p, stamp())).Should(MatchError("signature issue date is missing"))
+	})
+
+	It("should fail if the statement version is below the minimum version", func() {
+		policy.MinVersions = map[string]int{"products.list": 2}
+		Expect(checker().Check(p, stamp())).Should(MatchError("statement version 1 is below the minimum version 2"))
+	})
+
+	It("should accept statements at or above the minimum version", func() {
+		policy.MinVersions = map[string]int{"products.list": 2}
+		h.Version = 2
+		Expect(checker().Check(p, stamp())).Should(Succeed())
+	})
+
+	It("should fail if the statement id was revoked", func() {
+		policy.Revoked = check.NewRevocationList("products.list")
+		Expect(checker().Check(p, stamp())).Should(MatchError("statement products.list revoked"))
+	})
+
+	It("should fail if the payload digest was revoked", func() {
+		l := &check.RevocationList{}
+		policy.Revoked = l
+		Expect(checker().Check(p, stamp())).Should(Succeed())
+
+		l.Revoke(check.Digest(p))
+		Expect(checker().Check(p, stamp())).Should(MatchError("statement revoked"))
+	})
+
+})
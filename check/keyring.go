@@ -0,0 +1,136 @@
+package check
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// Key a key held by a Keyring
+	Key struct {
+		// ID the key id, matched against the kid found in the signature header
+		ID string
+		// Checker checks the signatures made with this key
+		Checker SignatureChecker
+		// ActivatesAt signatures are rejected before this time, zero means active since ever
+		ActivatesAt time.Time
+		// RetiresAt signatures are rejected from this time on, zero means never retired
+		RetiresAt time.Time
+	}
+
+	// Keyring checks stamped signatures (see Stamp) with the key identified by the kid in their header,
+	// allowing several keys to be active at once while the secret is rotated
+	Keyring struct {
+		// Policy validates the header of every signature checked by the keyring
+		Policy Policy
+		// Verified is called with the id of the key that verified each signature
+		Verified func(kid string)
+
+		mu   sync.RWMutex
+		keys map[string]Key
+		uses map[string]uint64
+	}
+)
+
+// NewKeyring returns a keyring holding the given keys
+func NewKeyring(p Policy, keys ...Key) *Keyring {
+	k := &Keyring{Policy: p}
+	for _, key := range keys {
+		k.Add(key)
+	}
+
+	return k
+}
+
+// Add adds a key to the keyring, replacing any key with the same id
+func (k *Keyring) Add(key Key) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.keys == nil {
+		k.keys = map[string]Key{}
+	}
+
+	k.keys[key.ID] = key
+}
+
+// Remove removes a key from the keyring
+func (k *Keyring) Remove(id string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	delete(k.keys, id)
+}
+
+// Uses returns the number of signatures verified by each key since it was added
+func (k *Keyring) Uses() map[string]uint64 {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	uses := make(map[string]uint64, len(k.keys))
+	for id := range k.keys {
+		uses[id] = k.uses[id]
+	}
+
+	return uses
+}
+
+// Check checks a stamped signature with the key identified by its header
+func (k *Keyring) Check(payload, s []byte) error {
+	if len(payload) == 0 {
+		return fmt.Errorf("payload cannot be empty")
+	}
+
+	h, signed, raw, err := Unstamp(payload, s)
+	if err != nil {
+		return err
+	}
+
+	if h.KeyID == "" {
+		return fmt.Errorf("signature key id is missing")
+	}
+
+	k.mu.RLock()
+	key, ok := k.keys[h.KeyID]
+	k.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown key %s", h.KeyID)
+	}
+
+	t := k.Policy.now()
+
+	if !key.ActivatesAt.IsZero() && t.Before(key.ActivatesAt) {
+		return fmt.Errorf("key %s is not active yet", key.ID)
+	}
+
+	if !key.RetiresAt.IsZero() && !t.Before(key.RetiresAt) {
+		return fmt.Errorf("key %s is retired", key.ID)
+	}
+
+	err = key.Checker.Check(signed, raw)
+	if err != nil {
+		return err
+	}
+
+	err = k.Policy.Validate(h, payload)
+	if err != nil {
+		return err
+	}
+
+	k.verified(key.ID)
+	return nil
+}
+
+func (k *Keyring) verified(id string) {
+	k.mu.Lock()
+	if k.uses == nil {
+		k.uses = map[string]uint64{}
+	}
+	k.uses[id]++
+	k.mu.Unlock()
+
+	if k.Verified != nil {
+		k.Verified(id)
+	}
+}
@@ -0,0 +1,100 @@
+package check_test
+
+import (
+	"time"
+
+	"github.com/at-silva/ddapi/check"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Keyring", func() {
+
+	var (
+		now     time.Time
+		keyring *check.Keyring
+		p       []byte
+		stamp   func(kid string, secret []byte) []byte
+	)
+
+	BeforeEach(func() {
+		now = time.Unix(1600000000, 0)
+		p = []byte("select name, id from product where id = :id")
+
+		keyring = check.NewKeyring(check.Policy{Now: func() time.Time { return now }},
+			check.Key{
+				ID:        "2019",
+				Checker:   check.Sha256HMAC([]byte("old secret")),
+				RetiresAt: now.Add(time.Hour),
+			},
+			check.Key{
+				ID:          "2020",
+				Checker:     check.Sha256HMAC([]byte("new secret")),
+				ActivatesAt: now.Add(-time.Hour),
+			},
+		)
+
+		stamp = func(kid string, secret []byte) []byte {
+			s, err := check.Stamp(check.Header{KeyID: kid, IssuedAt: now.Unix()}, p, check.Sha256HMACSigner(secret))
+			Expect(err).ShouldNot(HaveOccurred())
+			return s
+		}
+	})
+
+	It("should accept signatures made with any active key", func() {
+		Expect(keyring.Check(p, stamp("2019", []byte("old secret")))).Should(Succeed())
+		Expect(keyring.Check(p, stamp("2020", []byte("new secret")))).Should(Succeed())
+	})
+
+	It("should report which key verified each signature", func() {
+		var verified []string
+		keyring.Verified = func(kid string) { verified = append(verified, kid) }
+
+		Expect(keyring.Check(p, stamp("2019", []byte("old secret")))).Should(Succeed())
+		Expect(keyring.Check(p, stamp("2020", []byte("new secret")))).Should(Succeed())
+		Expect(keyring.Check(p, stamp("2020", []byte("new secret")))).Should(Succeed())
+		Expect(keyring.Check(p, stamp("2020", []byte("old secret")))).ShouldNot(Succeed())
+
+		Expect(verified).Should(Equal([]string{"2019", "2020", "2020"}))
+		Expect(keyring.Uses()).Should(Equal(map[string]uint64{"2019": 1, "2020": 2}))
+	})
+
+	It("should fail if the signature was made with another key", func() {
+		Expect(keyring.Check(p, stamp("2020", []byte("old secret")))).Should(MatchError("invalid signature"))
+	})
+
+	It("should fail if the signature has no key id", func() {
+		Expect(keyring.Check(p, stamp("", []byte("new secret")))).Should(MatchError("signature key id is missing"))
+	})
+
+	It("should fail if the key is unknown", func() {
+		Expect(keyring.Check(p, stamp("2021", []byte("new secret")))).Should(MatchError("unknown key 2021"))
+	})
+
+	It("should fail if the key was removed", func() {
+		keyring.Remove("2019")
+		Expect(keyring.Check(p, stamp("2019", []byte("old secret")))).Should(MatchError("unknown key 2019"))
+		Expect(keyring.Uses()).ShouldNot(HaveKey("2019"))
+	})
+
+	It("should fail if the key is not active yet", func() {
+		keyring.Add(check.Key{ID: "2021", Checker: check.Sha256HMAC([]byte("next secret")), ActivatesAt: now.Add(time.Minute)})
+		Expect(keyring.Check(p, stamp("2021", []byte("next secret")))).Should(MatchError("key 2021 is not active yet"))
+	})
+
+	It("should fail if the key is retired", func() {
+		now = now.Add(time.Hour)
+		Expect(keyring.Check(p, stamp("2019", []byte("old secret")))).Should(MatchError("key 2019 is retired"))
+	})
+
+	It("should validate the signature header against its policy", func() {
+		keyring.Policy.Revoked = check.NewRevocationList(check.Digest(p))
+		Expect(keyring.Check(p, stamp("2020", []byte("new secret")))).Should(MatchError("statement revoked"))
+	})
+
+	It("should fail if the payload is empty", func() {
+		Expect(keyring.Check([]byte{}, stamp("2020", []byte("new secret")))).ShouldNot(Succeed())
+	})
+
+})
@@ -15,6 +15,15 @@ type (
 
 	// Signature checker function type
 	Signature func(payload, signature []byte) error
+
+	// Signer represents a payload signer
+	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Signer
+	Signer interface {
+		Sign(payload []byte) ([]byte, error)
+	}
+
+	// Sign signer function type
+	Sign func(payload []byte) ([]byte, error)
 )
 
 // Check checks the signature of a given payload
@@ -22,6 +31,11 @@ func (f Signature) Check(p, s []byte) error {
 	return f(p, s)
 }
 
+// Sign signs the given payload
+func (f Sign) Sign(p []byte) ([]byte, error) {
+	return f(p)
+}
+
 // Sha256HMAC returns a hmac/sha256 base query validator
 func Sha256HMAC(secret []byte) Signature {
 	return func(p, s []byte) error {
@@ -47,3 +61,20 @@ func Sha256HMAC(secret []byte) Signature {
 		return nil
 	}
 }
+
+// Sha256HMACSigner returns a hmac/sha256 signer, the counterpart of Sha256HMAC
+func Sha256HMACSigner(secret []byte) Sign {
+	return func(p []byte) ([]byte, error) {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("payload cannot be empty")
+		}
+
+		hash := hmac.New(sha256.New, secret)
+		_, err := hash.Write(p)
+		if err != nil {
+			return nil, fmt.Errorf("could not write to hash stream: %w", err)
+		}
+
+		return hash.Sum(nil), nil
+	}
+}
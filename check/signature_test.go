@@ -66,4 +66,23 @@ var _ = Describe("SignatureChecker", func() {
 
 	})
 
+	Describe("Sha256HMACSigner", func() {
+
+		It("should produce signatures accepted by Sha256HMAC", func() {
+			key := []byte("my secret key")
+			q := []byte("select name, id from users where id = :id")
+
+			s, err := check.Sha256HMACSigner(key).Sign(q)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(hex.EncodeToString(s)).Should(Equal("e5ae3948a3345025bb29b70fd4df2a178aa3f528a50d3cfffe569d80968adfff"))
+			Expect(check.Sha256HMAC(key).Check(q, s)).Should(Succeed())
+		})
+
+		It("should fail if the payload is empty", func() {
+			_, err := check.Sha256HMACSigner([]byte("my secret key")).Sign([]byte{})
+			Expect(err).Should(HaveOccurred())
+		})
+
+	})
+
 })
@@ -0,0 +1,53 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Header holds the metadata carried by a stamped signature, it is covered by the signature along with the payload
+type Header struct {
+	KeyID     string `json:"kid,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Version   int    `json:"ver,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// Stamp signs the header along with the payload, the resulting signature is the JSON encoded header
+// immediately followed by the raw signature
+func Stamp(h Header, payload []byte, s Signer) ([]byte, error) {
+	hb, err := json.Marshal(h)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal signature header: %w", err)
+	}
+
+	raw, err := s.Sign(signed(hb, payload))
+	if err != nil {
+		return nil, fmt.Errorf("could not sign payload: %w", err)
+	}
+
+	return append(hb, raw...), nil
+}
+
+// Unstamp splits a stamped signature into its header, the bytes covered by the raw signature and the raw signature
+func Unstamp(payload, signature []byte) (Header, []byte, []byte, error) {
+	var h Header
+
+	dec := json.NewDecoder(bytes.NewReader(signature))
+	err := dec.Decode(&h)
+	if err != nil {
+		return h, nil, nil, fmt.Errorf("could not decode signature header: %w", err)
+	}
+
+	n := dec.InputOffset()
+	return h, signed(signature[:n], payload), signature[n:], nil
+}
+
+func signed(header, payload []byte) []byte {
+	s := make([]byte, 0, len(header)+len(payload))
+	s = append(s, header...)
+	return append(s, payload...)
+}
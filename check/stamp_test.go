@@ -0,0 +1,67 @@
+package check_test
+
+import (
+	"errors"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/check/checkfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Stamp", func() {
+
+	var (
+		fakeSigner *checkfakes.FakeSigner
+		h          check.Header
+		p          []byte
+	)
+
+	BeforeEach(func() {
+		fakeSigner = new(checkfakes.FakeSigner)
+		fakeSigner.SignReturns([]byte("raw-signature"), nil)
+		h = check.Header{ID: "products.insert", Version: 2, IssuedAt: 1600000000, ExpiresAt: 1700000000}
+		p = []byte("insert into product(name) values(:name)")
+	})
+
+	It("should sign the header along with the payload", func() {
+		s, err := check.Stamp(h, p, fakeSigner)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(s)).Should(Equal(`{"id":"products.insert","ver":2,"iat":1600000000,"exp":1700000000}raw-signature`))
+		Expect(string(fakeSigner.SignArgsForCall(0))).Should(Equal(`{"id":"products.insert","ver":2,"iat":1600000000,"exp":1700000000}` + string(p)))
+	})
+
+	It("should be reversible", func() {
+		s, err := check.Stamp(h, p, fakeSigner)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		uh, signed, raw, err := check.Unstamp(p, s)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(uh).Should(Equal(h))
+		Expect(signed).Should(Equal(fakeSigner.SignArgsForCall(0)))
+		Expect(string(raw)).Should(Equal("raw-signature"))
+	})
+
+	It("should keep raw signatures starting with whitespace intact", func() {
+		fakeSigner.SignReturns([]byte(" \n raw-signature"), nil)
+		s, err := check.Stamp(h, p, fakeSigner)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, _, raw, err := check.Unstamp(p, s)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(raw)).Should(Equal(" \n raw-signature"))
+	})
+
+	It("should fail if the signer fails", func() {
+		fakeSigner.SignReturns(nil, errors.New("no key"))
+		_, err := check.Stamp(h, p, fakeSigner)
+		Expect(err).Should(MatchError("could not sign payload: no key"))
+	})
+
+	It("should fail if the signature has no header", func() {
+		_, _, _, err := check.Unstamp(p, []byte("raw-signature"))
+		Expect(err).Should(MatchError(ContainSubstring("could not decode signature header")))
+	})
+
+})
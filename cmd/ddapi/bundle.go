@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/at-silva/ddapi/statement"
+)
+
+const tsManifest = `// Code generated by ddapi bundle. DO NOT EDIT.
+
+export const statements = %s as const;
+
+export type StatementId = keyof typeof statements;
+`
+
+func bundle(args []string, _ io.Reader, stdout, stderr io.Writer) error {
+	var (
+		key, dir, out, format, encrypt string
+		legacy                         bool
+		sf                             stampFlags
+		lf                             lintFlags
+	)
+
+	fs := newFlagSet("bundle", "", stderr)
+	fs.StringVar(&key, "key", "", "PEM encoded private key (ed25519, ecdsa P-256 or rsa) or hmac secret file")
+	fs.StringVar(&dir, "dir", "", "directory holding the <id>.sql statements and their <id>.json params schemas")
+	fs.StringVar(&out, "out", "", "manifest file, defaults to stdout")
+	fs.StringVar(&format, "format", "", "manifest format, json or ts, inferred from -out by default")
+	fs.StringVar(&encrypt, "encrypt", "", "base64 encoded AES key file, encrypts the signed statements")
+	fs.BoolVar(&legacy, "legacy", false, "sign the sql and params schema separately (sqlSignature/paramsSchemaSignature)")
+	sf.register(fs, false)
+	lf.register(fs, true)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if dir == "" {
+		return fmt.Errorf("missing statements directory, see -dir")
+	}
+
+	if format == "" {
+		format = "json"
+		if ext := filepath.Ext(out); ext == ".ts" {
+			format = "ts"
+		}
+	}
+
+	if format != "json" && format != "ts" {
+		return fmt.Errorf("invalid format %q, expected json or ts", format)
+	}
+
+	m, err := statement.Dir(dir)
+	if err != nil {
+		return err
+	}
+
+	o, err := signOptions(key, encrypt, legacy, sf.header(time.Now()), lf.linter())
+	if err != nil {
+		return err
+	}
+
+	res, err := m.Bundle(o)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	err = writeJSON(&b, res)
+	if err != nil {
+		return err
+	}
+
+	if format == "ts" {
+		manifest := fmt.Sprintf(tsManifest, bytes.TrimSpace(b.Bytes()))
+		b.Reset()
+		b.WriteString(manifest)
+	}
+
+	if out == "" {
+		_, err = b.WriteTo(stdout)
+		return err
+	}
+
+	err = ioutil.WriteFile(out, b.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("could not write manifest: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/statement"
+)
+
+type inspection struct {
+	Statement statement.Statement `json:"statement"`
+	Header    *check.Header       `json:"header,omitempty"`
+	Digest    string              `json:"digest"`
+	Encrypted bool                `json:"encrypted,omitempty"`
+	Legacy    bool                `json:"legacy,omitempty"`
+}
+
+func inspect(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var decrypt string
+
+	fs := newFlagSet("inspect", "[request.json|manifest.json]", stderr)
+	fs.StringVar(&decrypt, "decrypt", "", "base64 encoded AES key file, required by encrypted statements")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	c, err := loadCipher(decrypt)
+	if err != nil {
+		return err
+	}
+
+	ss, manifest, err := readSigned(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	res := make(map[string]inspection, len(ss))
+	for _, id := range ids(ss) {
+		i, err := inspectSigned(c, ss[id])
+		if err != nil {
+			return fmt.Errorf("could not inspect %s: %w", id, err)
+		}
+		res[id] = i
+	}
+
+	if !manifest {
+		for _, i := range res {
+			return writeJSON(stdout, i)
+		}
+	}
+
+	return writeJSON(stdout, res)
+}
+
+// inspectSigned decodes a signed statement without checking its signature
+func inspectSigned(c statement.Cipher, s statement.Signed) (inspection, error) {
+	if s.Statement == "" && s.EncryptedStatement == "" {
+		return inspection{
+			Statement: statement.Statement{Kind: statement.KindOf(s.SQL), SQL: s.SQL, ParamsSchema: s.ParamsSchema},
+			Header:    header([]byte(s.SQL), s.SQLSignature),
+			Digest:    check.Digest([]byte(s.SQL)),
+			Legacy:    true,
+		}, nil
+	}
+
+	e, err := envelope(s, c)
+	if err != nil {
+		return inspection{}, err
+	}
+
+	st, err := statement.Decode(e)
+	if err != nil {
+		return inspection{}, err
+	}
+
+	return inspection{
+		Statement: st,
+		Header:    header([]byte(e), s.StatementSignature),
+		Digest:    check.Digest([]byte(e)),
+		Encrypted: s.EncryptedStatement != "",
+	}, nil
+}
+
+// header returns the header of a stamped signature, or nil when the signature is not stamped
+func header(p []byte, sig string) *check.Header {
+	s, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil || len(s) == 0 || s[0] != '{' {
+		return nil
+	}
+
+	h, _, _, err := check.Unstamp(p, s)
+	if err != nil {
+		return nil
+	}
+
+	return &h
+}
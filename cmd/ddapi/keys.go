@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/statement"
+)
+
+type stampFlags struct {
+	stamp   bool
+	kid     string
+	id      string
+	version int
+	ttl     time.Duration
+}
+
+func (f *stampFlags) register(fs *flag.FlagSet, id bool) {
+	fs.BoolVar(&f.stamp, "stamp", false, "stamp the signature with its issue date, implied by -kid, -version and -ttl")
+	fs.StringVar(&f.kid, "kid", "", "signing key id carried by the signature header")
+	fs.IntVar(&f.version, "version", 0, "statement version carried by the signature header")
+	fs.DurationVar(&f.ttl, "ttl", 0, "signature lifetime, e.g. 720h")
+	if id {
+		fs.StringVar(&f.id, "id", "", "statement id carried by the signature header")
+	}
+}
+
+// header returns the signature header, or nil when signatures are not stamped
+func (f *stampFlags) header(now time.Time) *check.Header {
+	if !f.stamp && f.kid == "" && f.id == "" && f.version == 0 && f.ttl == 0 {
+		return nil
+	}
+
+	h := check.Header{KeyID: f.kid, ID: f.id, Version: f.version, IssuedAt: now.Unix()}
+	if f.ttl > 0 {
+		h.ExpiresAt = now.Add(f.ttl).Unix()
+	}
+
+	return &h
+}
+
+// loadSigner loads a PEM encoded private key, any other file is used as a hmac/sha256 secret
+func loadSigner(path string) (check.Signer, error) {
+	b, err := readKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if block, _ := pem.Decode(b); block != nil {
+		return check.ParsePrivatePEM(b)
+	}
+
+	return check.Sha256HMACSigner(secret(b)), nil
+}
+
+// loadChecker loads a JWKS document (.json), a PEM encoded public key or a hmac/sha256 secret,
+// JWKS keyrings always expect stamped signatures
+func loadChecker(path string, stamped bool, p check.Policy) (check.SignatureChecker, error) {
+	if filepath.Ext(path) == ".json" {
+		return check.LoadJWKS(path, p)
+	}
+
+	b, err := readKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var sc check.SignatureChecker = check.Sha256HMAC(secret(b))
+	if block, _ := pem.Decode(b); block != nil {
+		sc, err = check.ParsePEM(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if stamped {
+		sc = check.Expiring(sc, p)
+	}
+
+	return sc, nil
+}
+
+// loadCipher loads a base64 encoded AES key, raw keys are not accepted since a 24 or 32 bytes file could
+// be either of them
+func loadCipher(path string) (statement.Cipher, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	b, err := readKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(b)))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode encryption key: %w", err)
+	}
+
+	return statement.AESGCM(k)
+}
+
+func readKey(path string) ([]byte, error) {
+	if path == "" {
+		return nil, fmt.Errorf("missing key, see -key")
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read key: %w", err)
+	}
+
+	return b, nil
+}
+
+// secret strips the trailing new line most editors add to text files
+func secret(b []byte) []byte {
+	b = bytes.TrimSuffix(b, []byte("\n"))
+	return bytes.TrimSuffix(b, []byte("\r"))
+}
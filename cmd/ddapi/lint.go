@@ -0,0 +1,156 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/at-silva/ddapi/lint"
+)
+
+type (
+	scopes []lint.Scope
+
+	lintFlags struct {
+		skip   bool
+		scopes scopes
+		except string
+	}
+)
+
+func (s *scopes) String() string {
+	res := make([]string, len(*s))
+	for i, sc := range *s {
+		res[i] = sc.Column + "=" + strings.Join(sc.Claims, ",")
+	}
+
+	return strings.Join(res, " ")
+}
+
+// Set parses a column[=claim,...] scope
+func (s *scopes) Set(v string) error {
+	col, claims := v, ""
+	if i := strings.IndexByte(v, '='); i >= 0 {
+		col, claims = v[:i], v[i+1:]
+	}
+
+	if col == "" {
+		return fmt.Errorf("missing scope column")
+	}
+
+	sc := lint.Scope{Column: col}
+	if claims != "" {
+		sc.Claims = strings.Split(claims, ",")
+	}
+
+	*s = append(*s, sc)
+	return nil
+}
+
+func (f *lintFlags) register(fs *flag.FlagSet, skip bool) {
+	fs.Var(&f.scopes, "scope", "require tables to be filtered by column = :claim, as column[=claim,...] (repeatable)")
+	fs.StringVar(&f.except, "except", "", "comma separated tables exempt from the scopes")
+	if skip {
+		fs.BoolVar(&f.skip, "no-lint", false, "sign statements without linting them")
+	}
+}
+
+// linter returns the default linter configured with the given scopes, or nil when linting is skipped
+func (f *lintFlags) linter() lint.Linter {
+	if f.skip {
+		return nil
+	}
+
+	ss := make([]lint.Scope, len(f.scopes))
+	for i, s := range f.scopes {
+		if f.except != "" {
+			s.Except = strings.Split(f.except, ",")
+		}
+		ss[i] = s
+	}
+
+	return lint.Default(ss...)
+}
+
+func lintCmd(args []string, _ io.Reader, stdout, stderr io.Writer) error {
+	var lf lintFlags
+
+	fs := newFlagSet("lint", "path...", stderr)
+	lf.register(fs, false)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("missing statements, expected .sql files or directories")
+	}
+
+	paths, err := sqlFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	l := lf.linter()
+	failed := 0
+	for _, p := range paths {
+		q, err := ioutil.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("could not read statement: %w", err)
+		}
+
+		err = l.Lint(string(q))
+		if err == nil {
+			fmt.Fprintf(stdout, "%s: ok\n", p)
+			continue
+		}
+
+		failed++
+		ps, ok := err.(lint.Problems)
+		if !ok {
+			fmt.Fprintf(stdout, "%s: %v\n", p, err)
+			continue
+		}
+
+		for _, pr := range ps {
+			fmt.Fprintf(stdout, "%s: %s\n", p, pr)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d statements have problems", failed, len(paths))
+	}
+
+	return nil
+}
+
+// sqlFiles returns the given .sql files along with the ones found in the given directories
+func sqlFiles(args []string) ([]string, error) {
+	var res []string
+	for _, a := range args {
+		err := filepath.Walk(a, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !fi.IsDir() && (p == a || filepath.Ext(p) == ".sql") {
+				res = append(res, p)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read statements: %w", err)
+		}
+	}
+
+	sort.Strings(res)
+	return res, nil
+}
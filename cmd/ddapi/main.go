@@ -0,0 +1,74 @@
+//Package main contains the ddapi command, used to sign, verify, bundle, inspect and lint DDAPI statements
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: ddapi <command> [flags]
+
+commands:
+  sign     sign a statement (.sql file and its params schema)
+  verify   verify the signature of a signed statement or request
+  bundle   sign every statement in a directory into a JSON or TypeScript manifest
+  inspect  print the decoded statement envelope and signature header
+  lint     check statement files for DDL, multiple statements, unbounded writes and missing scopes
+
+run ddapi <command> -h for the flags of each command
+`
+
+type command func(args []string, stdin io.Reader, stdout, stderr io.Writer) error
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ddapi: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return flag.ErrHelp
+	}
+
+	commands := map[string]command{
+		"sign":    sign,
+		"verify":  verify,
+		"bundle":  bundle,
+		"inspect": inspect,
+		"lint":    lintCmd,
+	}
+
+	c, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+			fmt.Fprint(stderr, usage)
+			return flag.ErrHelp
+		}
+
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	return c(args[1:], stdin, stdout, stderr)
+}
+
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: ddapi %s [flags] %s\n\nflags:\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/handler"
+	"github.com/at-silva/ddapi/handler/handlerfakes"
+	"github.com/at-silva/ddapi/statement"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ddapi", func() {
+
+	var (
+		dir    string
+		secret string
+		stdin  *bytes.Buffer
+		stdout *bytes.Buffer
+		stderr *bytes.Buffer
+		write  func(name, content string) string
+		ddapi  func(args ...string) error
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "ddapi-cmd")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		stdin, stdout, stderr = new(bytes.Buffer), new(bytes.Buffer), new(bytes.Buffer)
+
+		write = func(name, content string) string {
+			path := filepath.Join(dir, name)
+			Expect(os.MkdirAll(filepath.Dir(path), 0700)).Should(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(content), 0600)).Should(Succeed())
+			return path
+		}
+
+		ddapi = func(args ...string) error {
+			stdout.Reset()
+			return run(args, stdin, stdout, stderr)
+		}
+
+		secret = write("secret", "secret\n")
+		write("statements/products/insert.sql", "insert into product(name) values(:name)")
+		write("statements/products/insert.json", `{"type":"object","required":["name"]}`)
+		write("statements/products/list.sql", "select * from product")
+		write("statements/products/list.json", `{}`)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).Should(Succeed())
+	})
+
+	Describe("sign", func() {
+		It("should produce a statement accepted by the handlers", func() {
+			Expect(ddapi("sign", "-key", secret, "-sql", filepath.Join(dir, "statements/products/insert.sql"), "-schema", filepath.Join(dir, "statements/products/insert.json"))).Should(Succeed())
+
+			body := map[string]interface{}{}
+			Expect(json.Unmarshal(stdout.Bytes(), &body)).Should(Succeed())
+			body["params"] = map[string]interface{}{"name": "Product 1"}
+			b, err := json.Marshal(body)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			fakeNext := new(handlerfakes.FakeHandler)
+			h := handler.DecodeRequest(handler.CheckSignatures(check.Sha256HMAC([]byte("secret")), fakeNext))
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/exec", bytes.NewReader(b))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			recorder := httptest.NewRecorder()
+			h.ServeHTTP(recorder, r)
+			Expect(recorder.Code).Should(Equal(http.StatusOK), recorder.Body.String())
+			Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
+		})
+
+		It("should sign with a private key and stamp the signature", func() {
+			pub, priv, err := ed25519.GenerateKey(rand.Reader)
+			Expect(err).ShouldNot(HaveOccurred())
+			der, err := x509.MarshalPKCS8PrivateKey(priv)
+			Expect(err).ShouldNot(HaveOccurred())
+			privPath := write("ed25519.pem", string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+			der, err = x509.MarshalPKIXPublicKey(pub)
+			Expect(err).ShouldNot(HaveOccurred())
+			pubPath := write("ed25519.pub.pem", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+
+			Expect(ddapi("sign", "-key", privPath, "-kid", "k1", "-id", "products/list", "-version", "3", "-ttl", "1h",
+				"-sql", filepath.Join(dir, "statements/products/list.sql"), "-schema", filepath.Join(dir, "statements/products/list.json"))).Should(Succeed())
+			signed := write("signed.json", stdout.String())
+
+			Expect(ddapi("verify", "-key", pubPath, "-stamped", signed)).Should(Succeed())
+			Expect(stdout.String()).Should(Equal(signed + ": ok\n"))
+
+			Expect(ddapi("verify", "-key", pubPath, signed)).ShouldNot(Succeed())
+
+			Expect(ddapi("inspect", signed)).Should(Succeed())
+			var i inspection
+			Expect(json.Unmarshal(stdout.Bytes(), &i)).Should(Succeed())
+			Expect(i.Statement.SQL).Should(Equal("select * from product"))
+			Expect(string(i.Statement.Kind)).Should(Equal("query"))
+			Expect(i.Header).ShouldNot(BeNil())
+			Expect(i.Header.KeyID).Should(Equal("k1"))
+			Expect(i.Header.ID).Should(Equal("products/list"))
+			Expect(i.Header.Version).Should(Equal(3))
+			Expect(i.Header.ExpiresAt - i.Header.IssuedAt).Should(Equal(int64(3600)))
+			Expect(i.Digest).Should(HavePrefix("sha256:"))
+		})
+
+		It("should encrypt the signed statement", func() {
+			key := write("aes.key", "MDEyMzQ1Njc4OWFiY2RlZg==\n")
+			Expect(ddapi("sign", "-key", secret, "-encrypt", key, "-sql", filepath.Join(dir, "statements/products/list.sql"), "-schema", filepath.Join(dir, "statements/products/list.json"))).Should(Succeed())
+			Expect(stdout.String()).Should(ContainSubstring("encryptedStatement"))
+			Expect(stdout.String()).ShouldNot(ContainSubstring(`"statement"`))
+			stdin.WriteString(stdout.String())
+			signed := write("signed.json", stdout.String())
+
+			Expect(ddapi("verify", "-key", secret, "-decrypt", key)).Should(Succeed())
+			Expect(stdout.String()).Should(Equal("-: ok\n"))
+
+			Expect(ddapi("inspect", signed)).Should(MatchError(ContainSubstring("statement is encrypted, see -decrypt")))
+		})
+
+		It("should decode base64 encryption keys saved without a trailing new line", func() {
+			key := write("aes.key", "MDEyMzQ1Njc4OWFiY2RlZg==")
+			Expect(ddapi("sign", "-key", secret, "-encrypt", key, "-sql", filepath.Join(dir, "statements/products/list.sql"), "-schema", filepath.Join(dir, "statements/products/list.json"))).Should(Succeed())
+
+			var s statement.Signed
+			Expect(json.Unmarshal(stdout.Bytes(), &s)).Should(Succeed())
+
+			c, err := statement.AESGCM([]byte("0123456789abcdef"))
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = c.Decrypt(s.EncryptedStatement)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should fail if the encryption key is not base64 encoded", func() {
+			key := write("aes.key", "0123456789abcdef")
+			err := ddapi("sign", "-key", secret, "-encrypt", key, "-sql", filepath.Join(dir, "statements/products/list.sql"), "-schema", filepath.Join(dir, "statements/products/list.json"))
+			Expect(err).Should(MatchError(ContainSubstring("could not create cipher")))
+		})
+
+		It("should fail if the kind is invalid", func() {
+			err := ddapi("sign", "-key", secret, "-kind", "ddl", "-sql", filepath.Join(dir, "statements/products/list.sql"), "-schema", filepath.Join(dir, "statements/products/list.json"))
+			Expect(err).Should(MatchError(`invalid kind "ddl", expected query or exec`))
+		})
+
+		It("should fail if the key is missing", func() {
+			err := ddapi("sign", "-sql", filepath.Join(dir, "statements/products/list.sql"), "-schema", filepath.Join(dir, "statements/products/list.json"))
+			Expect(err).Should(MatchError("missing key, see -key"))
+		})
+	})
+
+	Describe("verify", func() {
+		It("should report tampered statements", func() {
+			Expect(ddapi("sign", "-key", secret, "-legacy", "-sql", filepath.Join(dir, "statements/products/list.sql"), "-schema", filepath.Join(dir, "statements/products/list.json"))).Should(Succeed())
+			signed := write("signed.json", strings.Replace(stdout.String(), "from product", "from users", 1))
+
+			Expect(ddapi("verify", "-key", secret, signed)).Should(MatchError("1 of 1 signatures could not be verified"))
+			Expect(stdout.String()).Should(Equal(signed + ": could not validate sql signature: invalid signature\n"))
+		})
+	})
+
+	Describe("bundle", func() {
+		It("should sign every statement into a JSON manifest", func() {
+			manifest := filepath.Join(dir, "manifest.json")
+			Expect(ddapi("bundle", "-key", secret, "-kid", "k1", "-dir", filepath.Join(dir, "statements"), "-out", manifest)).Should(Succeed())
+
+			Expect(ddapi("verify", "-key", secret, "-stamped", manifest)).Should(Succeed())
+			Expect(stdout.String()).Should(Equal("products/insert: ok\nproducts/list: ok\n"))
+
+			Expect(ddapi("inspect", manifest)).Should(Succeed())
+			var i map[string]inspection
+			Expect(json.Unmarshal(stdout.Bytes(), &i)).Should(Succeed())
+			Expect(i).Should(HaveLen(2))
+			Expect(i["products/insert"].Header.ID).Should(Equal("products/insert"))
+		})
+
+		It("should sign pretty printed params schemas so they can be verified", func() {
+			write("statements/products/insert.json", "{\n  \"type\": \"object\",\n  \"required\": [\"name\"]\n}\n")
+			manifest := filepath.Join(dir, "manifest.json")
+
+			for _, legacy := range []bool{false, true} {
+				args := []string{"bundle", "-key", secret, "-dir", filepath.Join(dir, "statements"), "-out", manifest}
+				if legacy {
+					args = append(args, "-legacy")
+				}
+				Expect(ddapi(args...)).Should(Succeed())
+
+				Expect(ddapi("verify", "-key", secret, manifest)).Should(Succeed(), stdout.String())
+				Expect(stdout.String()).Should(Equal("products/insert: ok\nproducts/list: ok\n"))
+			}
+		})
+
+		It("should emit a TypeScript manifest", func() {
+			Expect(ddapi("bundle", "-key", secret, "-dir", filepath.Join(dir, "statements"), "-format", "ts")).Should(Succeed())
+			Expect(stdout.String()).Should(HavePrefix("// Code generated by ddapi bundle. DO NOT EDIT."))
+			Expect(stdout.String()).Should(ContainSubstring(`export const statements = {
+  "products/insert": {`))
+			Expect(stdout.String()).Should(ContainSubstring("} as const;"))
+		})
+
+		It("should fail if the directory holds an invalid statement", func() {
+			write("statements/broken.sql", "select 1")
+			err := ddapi("bundle", "-key", secret, "-dir", filepath.Join(dir, "statements"))
+			Expect(err).Should(MatchError(ContainSubstring("could not read params schema for statement broken")))
+		})
+	})
+
+	Describe("lint", func() {
+		It("should report the problems found in every statement", func() {
+			write("statements/products/purge.sql", "delete from product")
+
+			err := ddapi("lint", "-scope", "tenant_id=tenant_id,tid", "-except", "country", filepath.Join(dir, "statements"))
+			Expect(err).Should(MatchError("3 of 3 statements have problems"))
+			Expect(stdout.String()).Should(Equal(strings.Join([]string{
+				filepath.Join(dir, "statements/products/insert.sql") + ": scope: insert into product must set tenant_id to :tenant_id or :tid",
+				filepath.Join(dir, "statements/products/list.sql") + ": scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+				filepath.Join(dir, "statements/products/purge.sql") + ": bounded-writes: delete without a where clause",
+				filepath.Join(dir, "statements/products/purge.sql") + ": scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			}, "\n") + "\n"))
+		})
+
+		It("should accept valid statements", func() {
+			Expect(ddapi("lint", filepath.Join(dir, "statements/products/list.sql"))).Should(Succeed())
+			Expect(stdout.String()).Should(Equal(filepath.Join(dir, "statements/products/list.sql") + ": ok\n"))
+		})
+	})
+
+	It("should refuse to sign statements rejected by the linter", func() {
+		q := write("drop.sql", "drop table product")
+		schema := filepath.Join(dir, "statements/products/list.json")
+
+		err := ddapi("sign", "-key", secret, "-sql", q, "-schema", schema)
+		Expect(err).Should(MatchError("could not lint statement: no-ddl: drop statements are not allowed"))
+
+		Expect(ddapi("sign", "-key", secret, "-no-lint", "-sql", q, "-schema", schema)).Should(Succeed())
+
+		err = ddapi("bundle", "-key", secret, "-scope", "tenant_id", "-dir", filepath.Join(dir, "statements"))
+		Expect(err).Should(MatchError("could not bundle statement products/insert: could not lint statement: scope: insert into product must set tenant_id to :tenant_id"))
+	})
+
+	It("should fail on unknown commands", func() {
+		Expect(ddapi("deploy")).Should(MatchError(`unknown command "deploy"`))
+	})
+
+})
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/lint"
+	"github.com/at-silva/ddapi/statement"
+)
+
+func sign(args []string, _ io.Reader, stdout, stderr io.Writer) error {
+	var (
+		key, sqlPath, schemaPath, kind, meta, encrypt string
+		legacy                                        bool
+		sf                                            stampFlags
+		lf                                            lintFlags
+	)
+
+	fs := newFlagSet("sign", "", stderr)
+	fs.StringVar(&key, "key", "", "PEM encoded private key (ed25519, ecdsa P-256 or rsa) or hmac secret file")
+	fs.StringVar(&sqlPath, "sql", "", "statement sql file")
+	fs.StringVar(&schemaPath, "schema", "", "params schema file")
+	fs.StringVar(&kind, "kind", "", "statement kind, query or exec, inferred from the sql by default")
+	fs.StringVar(&meta, "meta", "", "statement metadata, as a JSON object")
+	fs.StringVar(&encrypt, "encrypt", "", "base64 encoded AES key file, encrypts the signed statement")
+	fs.BoolVar(&legacy, "legacy", false, "sign the sql and params schema separately (sqlSignature/paramsSchemaSignature)")
+	sf.register(fs, true)
+	lf.register(fs, true)
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	s, err := readStatement(sqlPath, schemaPath, kind, meta)
+	if err != nil {
+		return err
+	}
+
+	o, err := signOptions(key, encrypt, legacy, sf.header(time.Now()), lf.linter())
+	if err != nil {
+		return err
+	}
+
+	res, err := statement.Sign(s, o)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(stdout, res)
+}
+
+func readStatement(sqlPath, schemaPath, kind, meta string) (statement.Statement, error) {
+	var s statement.Statement
+
+	if sqlPath == "" || schemaPath == "" {
+		return s, fmt.Errorf("missing statement, see -sql and -schema")
+	}
+
+	q, err := ioutil.ReadFile(sqlPath)
+	if err != nil {
+		return s, fmt.Errorf("could not read sql: %w", err)
+	}
+
+	ps, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return s, fmt.Errorf("could not read params schema: %w", err)
+	}
+
+	var b bytes.Buffer
+	err = json.Compact(&b, ps)
+	if err != nil {
+		return s, fmt.Errorf("could not read params schema: invalid json")
+	}
+	ps = b.Bytes()
+
+	s = statement.Statement{Kind: statement.Kind(kind), SQL: string(q), ParamsSchema: ps}
+	switch s.Kind {
+	case "":
+		s.Kind = statement.KindOf(s.SQL)
+	case statement.Query, statement.Exec:
+	default:
+		return s, fmt.Errorf("invalid kind %q, expected query or exec", kind)
+	}
+
+	if meta != "" {
+		err = json.Unmarshal([]byte(meta), &s.Meta)
+		if err != nil {
+			return s, fmt.Errorf("could not unmarshal meta: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+func signOptions(key, encrypt string, legacy bool, h *check.Header, l lint.Linter) (statement.SignOptions, error) {
+	signer, err := loadSigner(key)
+	if err != nil {
+		return statement.SignOptions{}, err
+	}
+
+	c, err := loadCipher(encrypt)
+	if err != nil {
+		return statement.SignOptions{}, err
+	}
+
+	return statement.SignOptions{Signer: signer, Header: h, Cipher: c, Legacy: legacy, Linter: l}, nil
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/statement"
+)
+
+func verify(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var (
+		key, decrypt string
+		stamped      bool
+		maxAge       time.Duration
+	)
+
+	fs := newFlagSet("verify", "[request.json|manifest.json]", stderr)
+	fs.StringVar(&key, "key", "", "JWKS document (.json), PEM encoded public key or hmac secret file")
+	fs.StringVar(&decrypt, "decrypt", "", "base64 encoded AES key file, required by encrypted statements")
+	fs.BoolVar(&stamped, "stamped", false, "expect stamped signatures and validate their header, implied by JWKS keys")
+	fs.DurationVar(&maxAge, "max-age", 0, "reject stamped signatures issued more than max-age ago")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	sc, err := loadChecker(key, stamped, check.Policy{MaxAge: maxAge})
+	if err != nil {
+		return err
+	}
+
+	c, err := loadCipher(decrypt)
+	if err != nil {
+		return err
+	}
+
+	ss, _, err := readSigned(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, id := range ids(ss) {
+		err = verifySigned(sc, c, ss[id])
+		if err != nil {
+			failed++
+			fmt.Fprintf(stdout, "%s: %v\n", id, err)
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: ok\n", id)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d signatures could not be verified", failed, len(ss))
+	}
+
+	return nil
+}
+
+func verifySigned(sc check.SignatureChecker, c statement.Cipher, s statement.Signed) error {
+	if s.Statement == "" && s.EncryptedStatement == "" {
+		err := checkSignature(sc, []byte(s.SQL), s.SQLSignature)
+		if err != nil {
+			return fmt.Errorf("could not validate sql signature: %w", err)
+		}
+
+		// manifests are indented, the schema is signed, and sent by the frontend, in its compact form
+		var ps bytes.Buffer
+		err = json.Compact(&ps, s.ParamsSchema)
+		if err != nil {
+			return fmt.Errorf("could not read params schema: %w", err)
+		}
+
+		err = checkSignature(sc, ps.Bytes(), s.ParamsSchemaSignature)
+		if err != nil {
+			return fmt.Errorf("could not validate params schema signature: %w", err)
+		}
+
+		return nil
+	}
+
+	e, err := envelope(s, c)
+	if err != nil {
+		return err
+	}
+
+	_, err = statement.Decode(e)
+	if err != nil {
+		return err
+	}
+
+	err = checkSignature(sc, []byte(e), s.StatementSignature)
+	if err != nil {
+		return fmt.Errorf("could not validate statement signature: %w", err)
+	}
+
+	return nil
+}
+
+func checkSignature(sc check.SignatureChecker, p []byte, sig string) error {
+	s, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("could not decode signature: %w", err)
+	}
+
+	return sc.Check(p, s)
+}
+
+// envelope returns the encoded statement, decrypting it first when it is encrypted
+func envelope(s statement.Signed, c statement.Cipher) (string, error) {
+	if s.EncryptedStatement == "" {
+		return s.Statement, nil
+	}
+
+	if c == nil {
+		return "", fmt.Errorf("could not decode statement: statement is encrypted, see -decrypt")
+	}
+
+	p, err := c.Decrypt(s.EncryptedStatement)
+	if err != nil {
+		return "", fmt.Errorf("could not decode statement: %w", err)
+	}
+
+	return string(p), nil
+}
+
+// readSigned reads a single signed statement (or request), keyed by its file name, or a JSON manifest produced by bundle
+func readSigned(path string, stdin io.Reader) (map[string]statement.Signed, bool, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	name := path
+	if path == "" || path == "-" {
+		name = "-"
+		b, err = ioutil.ReadAll(stdin)
+	} else {
+		b, err = ioutil.ReadFile(path)
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read input: %w", err)
+	}
+
+	var s statement.Signed
+	err = json.Unmarshal(b, &s)
+	if err == nil && (s.Statement != "" || s.EncryptedStatement != "" || s.SQL != "") {
+		return map[string]statement.Signed{name: s}, false, nil
+	}
+
+	var m map[string]statement.Signed
+	err = json.Unmarshal(b, &m)
+	if err != nil || len(m) == 0 {
+		return nil, false, fmt.Errorf("could not read input: expected a signed statement or a JSON manifest")
+	}
+
+	return m, true, nil
+}
+
+func ids(m map[string]statement.Signed) []string {
+	res := make([]string, 0, len(m))
+	for id := range m {
+		res = append(res, id)
+	}
+	sort.Strings(res)
+
+	return res
+}
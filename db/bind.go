@@ -0,0 +1,151 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Named compiles a query with :name params into a query with the bindvars of the given sqlx bind type
+// (?, $1, @p1 or :arg1) along with its positional args. Array params are expanded for IN clauses, e.g.
+// "id in (:ids)". Strings, quoted identifiers and comments are left untouched, as are Postgres ::type casts and
+// MySQL := assignments, and \: is written as a literal colon
+func Named(bindType int, query string, arg map[string]interface{}) (string, []interface{}, error) {
+	var (
+		b    strings.Builder
+		args []interface{}
+	)
+
+	b.Grow(len(query))
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			n := quoted(query[i:], c)
+			b.WriteString(query[i : i+n])
+			i += n
+		case strings.HasPrefix(query[i:], "--"):
+			n := strings.IndexByte(query[i:], '\n')
+			if n < 0 {
+				n = len(query) - i
+			}
+			b.WriteString(query[i : i+n])
+			i += n
+		case strings.HasPrefix(query[i:], "/*"):
+			n := strings.Index(query[i+2:], "*/")
+			if n < 0 {
+				n = len(query) - i
+			} else {
+				n += 4
+			}
+			b.WriteString(query[i : i+n])
+			i += n
+		case c == '$' && dollarTag(query[i:]) != "":
+			tag := dollarTag(query[i:])
+			n := strings.Index(query[i+len(tag):], tag)
+			if n < 0 {
+				n = len(query) - i
+			} else {
+				n += 2 * len(tag)
+			}
+			b.WriteString(query[i : i+n])
+			i += n
+		case c == '\\' && strings.HasPrefix(query[i+1:], ":"):
+			b.WriteByte(':')
+			i += 2
+		case strings.HasPrefix(query[i:], "::") || strings.HasPrefix(query[i:], ":="):
+			b.WriteString(query[i : i+2])
+			i += 2
+		case c == ':' && i+1 < len(query) && nameStart(query[i+1]):
+			n := 1
+			for i+n < len(query) && namePart(query[i+n]) {
+				n++
+			}
+			name := query[i+1 : i+n]
+			i += n
+
+			v, ok := arg[name]
+			if !ok {
+				return "", nil, fmt.Errorf("could not bind param %s: not found in params", name)
+			}
+
+			expanded := []interface{}{v}
+			if v != nil {
+				_, in, err := sqlx.In("?", v)
+				if err != nil {
+					return "", nil, fmt.Errorf("could not bind param %s: %w", name, err)
+				}
+				expanded = in
+			}
+
+			for j := range expanded {
+				if j > 0 {
+					b.WriteString(", ")
+				}
+				bindvar(&b, bindType, len(args)+j+1)
+			}
+			args = append(args, expanded...)
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return b.String(), args, nil
+}
+
+// bindvar writes the n-th bindvar of the given bind type
+func bindvar(b *strings.Builder, bindType, n int) {
+	switch bindType {
+	case sqlx.DOLLAR:
+		b.WriteString("$" + strconv.Itoa(n))
+	case sqlx.AT:
+		b.WriteString("@p" + strconv.Itoa(n))
+	case sqlx.NAMED:
+		b.WriteString(":arg" + strconv.Itoa(n))
+	default:
+		b.WriteByte('?')
+	}
+}
+
+// quoted returns the length of the string or quoted identifier at the start of s, doubled quotes included
+func quoted(s string, q byte) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] != q {
+			continue
+		}
+
+		if i+1 < len(s) && s[i+1] == q {
+			i++
+			continue
+		}
+
+		return i + 1
+	}
+
+	return len(s)
+}
+
+// dollarTag returns the $tag$ opening the Postgres dollar quoted string at the start of s, if any
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '$':
+			return s[:i+1]
+		case !nameStart(s[i]) && (i == 1 || !namePart(s[i])):
+			return ""
+		}
+	}
+
+	return ""
+}
+
+func nameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func namePart(c byte) bool {
+	return nameStart(c) || (c >= '0' && c <= '9')
+}
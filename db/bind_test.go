@@ -0,0 +1,76 @@
+package db_test
+
+import (
+	"github.com/at-silva/ddapi/db"
+	"github.com/jmoiron/sqlx"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Named", func() {
+
+	It("should rebind the named params with the bindvars of the driver", func() {
+		for bindType, expected := range map[int]string{
+			sqlx.QUESTION: "select * from product where id = ? and name = ?",
+			sqlx.DOLLAR:   "select * from product where id = $1 and name = $2",
+			sqlx.AT:       "select * from product where id = @p1 and name = @p2",
+			sqlx.NAMED:    "select * from product where id = :arg1 and name = :arg2",
+		} {
+			q, args, err := db.Named(bindType, "select * from product where id = :id and name = :name", map[string]interface{}{"id": 1, "name": "Product 1"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(q).Should(Equal(expected))
+			Expect(args).Should(Equal([]interface{}{1, "Product 1"}))
+		}
+	})
+
+	It("should expand array params", func() {
+		q, args, err := db.Named(sqlx.DOLLAR, "select * from product where id in (:ids) and name <> :name", map[string]interface{}{
+			"ids":  []interface{}{float64(1), float64(2), float64(3)},
+			"name": "Product 1",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(q).Should(Equal("select * from product where id in ($1, $2, $3) and name <> $4"))
+		Expect(args).Should(Equal([]interface{}{float64(1), float64(2), float64(3), "Product 1"}))
+	})
+
+	It("should not expand []byte params", func() {
+		_, args, err := db.Named(sqlx.QUESTION, "select :b", map[string]interface{}{"b": []byte("abc")})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(args).Should(Equal([]interface{}{[]byte("abc")}))
+	})
+
+	It("should bind nil params", func() {
+		q, args, err := db.Named(sqlx.QUESTION, "update product set name = :name", map[string]interface{}{"name": nil})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(q).Should(Equal("update product set name = ?"))
+		Expect(args).Should(Equal([]interface{}{nil}))
+	})
+
+	It("should leave casts, assignments, strings, identifiers and comments untouched", func() {
+		q, args, err := db.Named(sqlx.DOLLAR, `select :id::int, ':a', "b:c", $$:d$$, $t$:e$t$, @x := 1 -- :f
+/* :g */ from t`, map[string]interface{}{"id": "1"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(q).Should(Equal(`select $1::int, ':a', "b:c", $$:d$$, $t$:e$t$, @x := 1 -- :f
+/* :g */ from t`))
+		Expect(args).Should(Equal([]interface{}{"1"}))
+	})
+
+	It("should write escaped colons as literal colons", func() {
+		q, args, err := db.Named(sqlx.QUESTION, `select * from event where at = time \:00 and id = :id`, map[string]interface{}{"id": 1})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(q).Should(Equal("select * from event where at = time :00 and id = ?"))
+		Expect(args).Should(Equal([]interface{}{1}))
+	})
+
+	It("should fail when a param is missing", func() {
+		_, _, err := db.Named(sqlx.QUESTION, "select * from product where id = :id", map[string]interface{}{})
+		Expect(err).Should(MatchError("could not bind param id: not found in params"))
+	})
+
+	It("should fail when an array param is empty", func() {
+		_, _, err := db.Named(sqlx.QUESTION, "select * from product where id in (:ids)", map[string]interface{}{"ids": []interface{}{}})
+		Expect(err).Should(MatchError("could not bind param ids: empty slice passed to 'in' query"))
+	})
+
+})
@@ -4,6 +4,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"reflect"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -16,13 +17,39 @@ type (
 	DB interface {
 		NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
 		NamedQueryContext(ctx context.Context, query string, arg interface{}) (Rows, error)
+		BeginTxx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+		Dialect() Dialect
+	}
+
+	// Tx represents a sqlx Tx
+	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Tx
+	Tx interface {
+		NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+		NamedQueryContext(ctx context.Context, query string, arg interface{}) (Rows, error)
+		Commit() error
+		Rollback() error
 	}
 
 	// Rows represents a sqlx Rows
 	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Rows
 	Rows interface {
 		MapScan(map[string]interface{}) error
+		SliceScan() ([]interface{}, error)
 		Next() bool
+		Close() error
+		Err() error
+		ColumnTypes() ([]ColumnType, error)
+	}
+
+	// ColumnType represents a sql.ColumnType
+	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . ColumnType
+	ColumnType interface {
+		Name() string
+		DatabaseTypeName() string
+		ScanType() reflect.Type
+		Nullable() (nullable, ok bool)
+		Length() (length int64, ok bool)
+		DecimalSize() (precision, scale int64, ok bool)
 	}
 )
 
@@ -36,9 +63,95 @@ type dbOp struct {
 }
 
 func (db dbOp) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
-	return db.DB.NamedExecContext(ctx, query, arg)
+	return namedExec(ctx, db.DB, query, arg)
 }
 
 func (db dbOp) NamedQueryContext(ctx context.Context, query string, arg interface{}) (Rows, error) {
-	return db.DB.NamedQueryContext(ctx, query, arg)
+	return wrapRows(namedQuery(ctx, db.DB, query, arg))
+}
+
+func (db dbOp) Dialect() Dialect {
+	return DialectOf(db.DriverName())
+}
+
+func (db dbOp) BeginTxx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx, err := db.DB.BeginTxx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return txOp{tx}, nil
+}
+
+type txOp struct {
+	*sqlx.Tx
+}
+
+func (tx txOp) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return namedExec(ctx, tx.Tx, query, arg)
+}
+
+func (tx txOp) NamedQueryContext(ctx context.Context, query string, arg interface{}) (Rows, error) {
+	return wrapRows(namedQuery(ctx, tx.Tx, query, arg))
+}
+
+// namedExec binds the named params with the bindvars of the driver, see Named
+func namedExec(ctx context.Context, e sqlx.ExtContext, query string, arg interface{}) (sql.Result, error) {
+	q, args, err := bind(e, query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.ExecContext(ctx, q, args...)
+}
+
+// namedQuery binds the named params with the bindvars of the driver, see Named
+func namedQuery(ctx context.Context, e sqlx.ExtContext, query string, arg interface{}) (*sqlx.Rows, error) {
+	q, args, err := bind(e, query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.QueryxContext(ctx, q, args...)
+}
+
+// bind compiles the query with Named for params maps, other args are bound by sqlx
+func bind(e sqlx.ExtContext, query string, arg interface{}) (string, []interface{}, error) {
+	bindType := sqlx.BindType(e.DriverName())
+	if m, ok := arg.(map[string]interface{}); ok {
+		return Named(bindType, query, m)
+	}
+
+	q, args, err := sqlx.Named(query, arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sqlx.Rebind(bindType, q), args, nil
+}
+
+type rowsOp struct {
+	*sqlx.Rows
+}
+
+func wrapRows(rows *sqlx.Rows, err error) (Rows, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return rowsOp{rows}, nil
+}
+
+func (rows rowsOp) ColumnTypes() ([]ColumnType, error) {
+	cts, err := rows.Rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]ColumnType, len(cts))
+	for i, ct := range cts {
+		res[i] = ct
+	}
+
+	return res, nil
 }
@@ -0,0 +1,96 @@
+package db_test
+
+import (
+	"context"
+
+	"github.com/at-silva/ddapi/db"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DB", func() {
+
+	var (
+		ctx = context.Background()
+		sdb *sqlx.DB
+		d   db.DB
+	)
+
+	BeforeEach(func() {
+		var err error
+		sdb, err = sqlx.Open("sqlite3", ":memory:")
+		Expect(err).ShouldNot(HaveOccurred())
+		sdb.SetMaxOpenConns(1)
+
+		_, err = sdb.Exec("create table product (id integer primary key, name text)")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		d = db.New(sdb)
+	})
+
+	AfterEach(func() {
+		Expect(sdb.Close()).Should(Succeed())
+	})
+
+	names := func(rows db.Rows, err error) []interface{} {
+		Expect(err).ShouldNot(HaveOccurred())
+		defer rows.Close()
+
+		var res []interface{}
+		for rows.Next() {
+			values, err := rows.SliceScan()
+			Expect(err).ShouldNot(HaveOccurred())
+			res = append(res, values...)
+		}
+		Expect(rows.Err()).ShouldNot(HaveOccurred())
+
+		return res
+	}
+
+	It("should bind named params", func() {
+		res, err := d.NamedExecContext(ctx, "insert into product(name) values(:name)", map[string]interface{}{"name": "Product 1"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(res.LastInsertId()).Should(Equal(int64(1)))
+
+		Expect(names(d.NamedQueryContext(ctx, "select name from product where id = :id", map[string]interface{}{"id": 1}))).
+			Should(Equal([]interface{}{"Product 1"}))
+	})
+
+	It("should expand array params in IN clauses", func() {
+		for _, name := range []string{"Product 1", "Product 2", "Product 3"} {
+			_, err := d.NamedExecContext(ctx, "insert into product(name) values(:name)", map[string]interface{}{"name": name})
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+
+		Expect(names(d.NamedQueryContext(ctx, "select name from product where id in (:ids) order by id", map[string]interface{}{"ids": []interface{}{1, 3}}))).
+			Should(Equal([]interface{}{"Product 1", "Product 3"}))
+	})
+
+	It("should not bind params inside strings", func() {
+		Expect(names(d.NamedQueryContext(ctx, "select :a || ':b'", map[string]interface{}{"a": "a"}))).
+			Should(Equal([]interface{}{"a:b"}))
+	})
+
+	It("should bind named params inside transactions", func() {
+		tx, err := d.BeginTxx(ctx, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, err = tx.NamedExecContext(ctx, "insert into product(name) values(:name)", map[string]interface{}{"name": "Product 1"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(names(tx.NamedQueryContext(ctx, "select name from product where name in (:names)", map[string]interface{}{"names": []interface{}{"Product 1"}}))).
+			Should(Equal([]interface{}{"Product 1"}))
+		Expect(tx.Commit()).Should(Succeed())
+	})
+
+	It("should bind structs through sqlx", func() {
+		_, err := d.NamedExecContext(ctx, "insert into product(name) values(:name)", struct {
+			Name string `db:"name"`
+		}{"Product 1"})
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+})
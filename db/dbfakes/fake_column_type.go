@@ -0,0 +1,448 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package dbfakes
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/at-silva/ddapi/db"
+)
+
+type FakeColumnType struct {
+	DatabaseTypeNameStub        func() string
+	databaseTypeNameMutex       sync.RWMutex
+	databaseTypeNameArgsForCall []struct {
+	}
+	databaseTypeNameReturns struct {
+		result1 string
+	}
+	databaseTypeNameReturnsOnCall map[int]struct {
+		result1 string
+	}
+	DecimalSizeStub        func() (int64, int64, bool)
+	decimalSizeMutex       sync.RWMutex
+	decimalSizeArgsForCall []struct {
+	}
+	decimalSizeReturns struct {
+		result1 int64
+		result2 int64
+		result3 bool
+	}
+	decimalSizeReturnsOnCall map[int]struct {
+		result1 int64
+		result2 int64
+		result3 bool
+	}
+	LengthStub        func() (int64, bool)
+	lengthMutex       sync.RWMutex
+	lengthArgsForCall []struct {
+	}
+	lengthReturns struct {
+		result1 int64
+		result2 bool
+	}
+	lengthReturnsOnCall map[int]struct {
+		result1 int64
+		result2 bool
+	}
+	NameStub        func() string
+	nameMutex       sync.RWMutex
+	nameArgsForCall []struct {
+	}
+	nameReturns struct {
+		result1 string
+	}
+	nameReturnsOnCall map[int]struct {
+		result1 string
+	}
+	NullableStub        func() (bool, bool)
+	nullableMutex       sync.RWMutex
+	nullableArgsForCall []struct {
+	}
+	nullableReturns struct {
+		result1 bool
+		result2 bool
+	}
+	nullableReturnsOnCall map[int]struct {
+		result1 bool
+		result2 bool
+	}
+	ScanTypeStub        func() reflect.Type
+	scanTypeMutex       sync.RWMutex
+	scanTypeArgsForCall []struct {
+	}
+	scanTypeReturns struct {
+		result1 reflect.Type
+	}
+	scanTypeReturnsOnCall map[int]struct {
+		result1 reflect.Type
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeColumnType) DatabaseTypeName() string {
+	fake.databaseTypeNameMutex.Lock()
+	ret, specificReturn := fake.databaseTypeNameReturnsOnCall[len(fake.databaseTypeNameArgsForCall)]
+	fake.databaseTypeNameArgsForCall = append(fake.databaseTypeNameArgsForCall, struct {
+	}{})
+	stub := fake.DatabaseTypeNameStub
+	fakeReturns := fake.databaseTypeNameReturns
+	fake.recordInvocation("DatabaseTypeName", []interface{}{})
+	fake.databaseTypeNameMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeColumnType) DatabaseTypeNameCallCount() int {
+	fake.databaseTypeNameMutex.RLock()
+	defer fake.databaseTypeNameMutex.RUnlock()
+	return len(fake.databaseTypeNameArgsForCall)
+}
+
+func (fake *FakeColumnType) DatabaseTypeNameCalls(stub func() string) {
+	fake.databaseTypeNameMutex.Lock()
+	defer fake.databaseTypeNameMutex.Unlock()
+	fake.DatabaseTypeNameStub = stub
+}
+
+func (fake *FakeColumnType) DatabaseTypeNameReturns(result1 string) {
+	fake.databaseTypeNameMutex.Lock()
+	defer fake.databaseTypeNameMutex.Unlock()
+	fake.DatabaseTypeNameStub = nil
+	fake.databaseTypeNameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeColumnType) DatabaseTypeNameReturnsOnCall(i int, result1 string) {
+	fake.databaseTypeNameMutex.Lock()
+	defer fake.databaseTypeNameMutex.Unlock()
+	fake.DatabaseTypeNameStub = nil
+	if fake.databaseTypeNameReturnsOnCall == nil {
+		fake.databaseTypeNameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.databaseTypeNameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeColumnType) DecimalSize() (int64, int64, bool) {
+	fake.decimalSizeMutex.Lock()
+	ret, specificReturn := fake.decimalSizeReturnsOnCall[len(fake.decimalSizeArgsForCall)]
+	fake.decimalSizeArgsForCall = append(fake.decimalSizeArgsForCall, struct {
+	}{})
+	stub := fake.DecimalSizeStub
+	fakeReturns := fake.decimalSizeReturns
+	fake.recordInvocation("DecimalSize", []interface{}{})
+	fake.decimalSizeMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeColumnType) DecimalSizeCallCount() int {
+	fake.decimalSizeMutex.RLock()
+	defer fake.decimalSizeMutex.RUnlock()
+	return len(fake.decimalSizeArgsForCall)
+}
+
+func (fake *FakeColumnType) DecimalSizeCalls(stub func() (int64, int64, bool)) {
+	fake.decimalSizeMutex.Lock()
+	defer fake.decimalSizeMutex.Unlock()
+	fake.DecimalSizeStub = stub
+}
+
+func (fake *FakeColumnType) DecimalSizeReturns(result1 int64, result2 int64, result3 bool) {
+	fake.decimalSizeMutex.Lock()
+	defer fake.decimalSizeMutex.Unlock()
+	fake.DecimalSizeStub = nil
+	fake.decimalSizeReturns = struct {
+		result1 int64
+		result2 int64
+		result3 bool
+	}{result1, result2, result3}
+}
+
+func (fake *FakeColumnType) DecimalSizeReturnsOnCall(i int, result1 int64, result2 int64, result3 bool) {
+	fake.decimalSizeMutex.Lock()
+	defer fake.decimalSizeMutex.Unlock()
+	fake.DecimalSizeStub = nil
+	if fake.decimalSizeReturnsOnCall == nil {
+		fake.decimalSizeReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 int64
+			result3 bool
+		})
+	}
+	fake.decimalSizeReturnsOnCall[i] = struct {
+		result1 int64
+		result2 int64
+		result3 bool
+	}{result1, result2, result3}
+}
+
+func (fake *FakeColumnType) Length() (int64, bool) {
+	fake.lengthMutex.Lock()
+	ret, specificReturn := fake.lengthReturnsOnCall[len(fake.lengthArgsForCall)]
+	fake.lengthArgsForCall = append(fake.lengthArgsForCall, struct {
+	}{})
+	stub := fake.LengthStub
+	fakeReturns := fake.lengthReturns
+	fake.recordInvocation("Length", []interface{}{})
+	fake.lengthMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeColumnType) LengthCallCount() int {
+	fake.lengthMutex.RLock()
+	defer fake.lengthMutex.RUnlock()
+	return len(fake.lengthArgsForCall)
+}
+
+func (fake *FakeColumnType) LengthCalls(stub func() (int64, bool)) {
+	fake.lengthMutex.Lock()
+	defer fake.lengthMutex.Unlock()
+	fake.LengthStub = stub
+}
+
+func (fake *FakeColumnType) LengthReturns(result1 int64, result2 bool) {
+	fake.lengthMutex.Lock()
+	defer fake.lengthMutex.Unlock()
+	fake.LengthStub = nil
+	fake.lengthReturns = struct {
+		result1 int64
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeColumnType) LengthReturnsOnCall(i int, result1 int64, result2 bool) {
+	fake.lengthMutex.Lock()
+	defer fake.lengthMutex.Unlock()
+	fake.LengthStub = nil
+	if fake.lengthReturnsOnCall == nil {
+		fake.lengthReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 bool
+		})
+	}
+	fake.lengthReturnsOnCall[i] = struct {
+		result1 int64
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeColumnType) Name() string {
+	fake.nameMutex.Lock()
+	ret, specificReturn := fake.nameReturnsOnCall[len(fake.nameArgsForCall)]
+	fake.nameArgsForCall = append(fake.nameArgsForCall, struct {
+	}{})
+	stub := fake.NameStub
+	fakeReturns := fake.nameReturns
+	fake.recordInvocation("Name", []interface{}{})
+	fake.nameMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeColumnType) NameCallCount() int {
+	fake.nameMutex.RLock()
+	defer fake.nameMutex.RUnlock()
+	return len(fake.nameArgsForCall)
+}
+
+func (fake *FakeColumnType) NameCalls(stub func() string) {
+	fake.nameMutex.Lock()
+	defer fake.nameMutex.Unlock()
+	fake.NameStub = stub
+}
+
+func (fake *FakeColumnType) NameReturns(result1 string) {
+	fake.nameMutex.Lock()
+	defer fake.nameMutex.Unlock()
+	fake.NameStub = nil
+	fake.nameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeColumnType) NameReturnsOnCall(i int, result1 string) {
+	fake.nameMutex.Lock()
+	defer fake.nameMutex.Unlock()
+	fake.NameStub = nil
+	if fake.nameReturnsOnCall == nil {
+		fake.nameReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.nameReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeColumnType) Nullable() (bool, bool) {
+	fake.nullableMutex.Lock()
+	ret, specificReturn := fake.nullableReturnsOnCall[len(fake.nullableArgsForCall)]
+	fake.nullableArgsForCall = append(fake.nullableArgsForCall, struct {
+	}{})
+	stub := fake.NullableStub
+	fakeReturns := fake.nullableReturns
+	fake.recordInvocation("Nullable", []interface{}{})
+	fake.nullableMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeColumnType) NullableCallCount() int {
+	fake.nullableMutex.RLock()
+	defer fake.nullableMutex.RUnlock()
+	return len(fake.nullableArgsForCall)
+}
+
+func (fake *FakeColumnType) NullableCalls(stub func() (bool, bool)) {
+	fake.nullableMutex.Lock()
+	defer fake.nullableMutex.Unlock()
+	fake.NullableStub = stub
+}
+
+func (fake *FakeColumnType) NullableReturns(result1 bool, result2 bool) {
+	fake.nullableMutex.Lock()
+	defer fake.nullableMutex.Unlock()
+	fake.NullableStub = nil
+	fake.nullableReturns = struct {
+		result1 bool
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeColumnType) NullableReturnsOnCall(i int, result1 bool, result2 bool) {
+	fake.nullableMutex.Lock()
+	defer fake.nullableMutex.Unlock()
+	fake.NullableStub = nil
+	if fake.nullableReturnsOnCall == nil {
+		fake.nullableReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 bool
+		})
+	}
+	fake.nullableReturnsOnCall[i] = struct {
+		result1 bool
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeColumnType) ScanType() reflect.Type {
+	fake.scanTypeMutex.Lock()
+	ret, specificReturn := fake.scanTypeReturnsOnCall[len(fake.scanTypeArgsForCall)]
+	fake.scanTypeArgsForCall = append(fake.scanTypeArgsForCall, struct {
+	}{})
+	stub := fake.ScanTypeStub
+	fakeReturns := fake.scanTypeReturns
+	fake.recordInvocation("ScanType", []interface{}{})
+	fake.scanTypeMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeColumnType) ScanTypeCallCount() int {
+	fake.scanTypeMutex.RLock()
+	defer fake.scanTypeMutex.RUnlock()
+	return len(fake.scanTypeArgsForCall)
+}
+
+func (fake *FakeColumnType) ScanTypeCalls(stub func() reflect.Type) {
+	fake.scanTypeMutex.Lock()
+	defer fake.scanTypeMutex.Unlock()
+	fake.ScanTypeStub = stub
+}
+
+func (fake *FakeColumnType) ScanTypeReturns(result1 reflect.Type) {
+	fake.scanTypeMutex.Lock()
+	defer fake.scanTypeMutex.Unlock()
+	fake.ScanTypeStub = nil
+	fake.scanTypeReturns = struct {
+		result1 reflect.Type
+	}{result1}
+}
+
+func (fake *FakeColumnType) ScanTypeReturnsOnCall(i int, result1 reflect.Type) {
+	fake.scanTypeMutex.Lock()
+	defer fake.scanTypeMutex.Unlock()
+	fake.ScanTypeStub = nil
+	if fake.scanTypeReturnsOnCall == nil {
+		fake.scanTypeReturnsOnCall = make(map[int]struct {
+			result1 reflect.Type
+		})
+	}
+	fake.scanTypeReturnsOnCall[i] = struct {
+		result1 reflect.Type
+	}{result1}
+}
+
+func (fake *FakeColumnType) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.databaseTypeNameMutex.RLock()
+	defer fake.databaseTypeNameMutex.RUnlock()
+	fake.decimalSizeMutex.RLock()
+	defer fake.decimalSizeMutex.RUnlock()
+	fake.lengthMutex.RLock()
+	defer fake.lengthMutex.RUnlock()
+	fake.nameMutex.RLock()
+	defer fake.nameMutex.RUnlock()
+	fake.nullableMutex.RLock()
+	defer fake.nullableMutex.RUnlock()
+	fake.scanTypeMutex.RLock()
+	defer fake.scanTypeMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeColumnType) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ db.ColumnType = new(FakeColumnType)
@@ -10,6 +10,30 @@ import (
 )
 
 type FakeDB struct {
+	BeginTxxStub        func(context.Context, *sql.TxOptions) (db.Tx, error)
+	beginTxxMutex       sync.RWMutex
+	beginTxxArgsForCall []struct {
+		arg1 context.Context
+		arg2 *sql.TxOptions
+	}
+	beginTxxReturns struct {
+		result1 db.Tx
+		result2 error
+	}
+	beginTxxReturnsOnCall map[int]struct {
+		result1 db.Tx
+		result2 error
+	}
+	DialectStub        func() db.Dialect
+	dialectMutex       sync.RWMutex
+	dialectArgsForCall []struct {
+	}
+	dialectReturns struct {
+		result1 db.Dialect
+	}
+	dialectReturnsOnCall map[int]struct {
+		result1 db.Dialect
+	}
 	NamedExecContextStub        func(context.Context, string, interface{}) (sql.Result, error)
 	namedExecContextMutex       sync.RWMutex
 	namedExecContextArgsForCall []struct {
@@ -44,6 +68,124 @@ type FakeDB struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeDB) BeginTxx(arg1 context.Context, arg2 *sql.TxOptions) (db.Tx, error) {
+	fake.beginTxxMutex.Lock()
+	ret, specificReturn := fake.beginTxxReturnsOnCall[len(fake.beginTxxArgsForCall)]
+	fake.beginTxxArgsForCall = append(fake.beginTxxArgsForCall, struct {
+		arg1 context.Context
+		arg2 *sql.TxOptions
+	}{arg1, arg2})
+	stub := fake.BeginTxxStub
+	fakeReturns := fake.beginTxxReturns
+	fake.recordInvocation("BeginTxx", []interface{}{arg1, arg2})
+	fake.beginTxxMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDB) BeginTxxCallCount() int {
+	fake.beginTxxMutex.RLock()
+	defer fake.beginTxxMutex.RUnlock()
+	return len(fake.beginTxxArgsForCall)
+}
+
+func (fake *FakeDB) BeginTxxCalls(stub func(context.Context, *sql.TxOptions) (db.Tx, error)) {
+	fake.beginTxxMutex.Lock()
+	defer fake.beginTxxMutex.Unlock()
+	fake.BeginTxxStub = stub
+}
+
+func (fake *FakeDB) BeginTxxArgsForCall(i int) (context.Context, *sql.TxOptions) {
+	fake.beginTxxMutex.RLock()
+	defer fake.beginTxxMutex.RUnlock()
+	argsForCall := fake.beginTxxArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeDB) BeginTxxReturns(result1 db.Tx, result2 error) {
+	fake.beginTxxMutex.Lock()
+	defer fake.beginTxxMutex.Unlock()
+	fake.BeginTxxStub = nil
+	fake.beginTxxReturns = struct {
+		result1 db.Tx
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDB) BeginTxxReturnsOnCall(i int, result1 db.Tx, result2 error) {
+	fake.beginTxxMutex.Lock()
+	defer fake.beginTxxMutex.Unlock()
+	fake.BeginTxxStub = nil
+	if fake.beginTxxReturnsOnCall == nil {
+		fake.beginTxxReturnsOnCall = make(map[int]struct {
+			result1 db.Tx
+			result2 error
+		})
+	}
+	fake.beginTxxReturnsOnCall[i] = struct {
+		result1 db.Tx
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDB) Dialect() db.Dialect {
+	fake.dialectMutex.Lock()
+	ret, specificReturn := fake.dialectReturnsOnCall[len(fake.dialectArgsForCall)]
+	fake.dialectArgsForCall = append(fake.dialectArgsForCall, struct {
+	}{})
+	stub := fake.DialectStub
+	fakeReturns := fake.dialectReturns
+	fake.recordInvocation("Dialect", []interface{}{})
+	fake.dialectMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDB) DialectCallCount() int {
+	fake.dialectMutex.RLock()
+	defer fake.dialectMutex.RUnlock()
+	return len(fake.dialectArgsForCall)
+}
+
+func (fake *FakeDB) DialectCalls(stub func() db.Dialect) {
+	fake.dialectMutex.Lock()
+	defer fake.dialectMutex.Unlock()
+	fake.DialectStub = stub
+}
+
+func (fake *FakeDB) DialectReturns(result1 db.Dialect) {
+	fake.dialectMutex.Lock()
+	defer fake.dialectMutex.Unlock()
+	fake.DialectStub = nil
+	fake.dialectReturns = struct {
+		result1 db.Dialect
+	}{result1}
+}
+
+func (fake *FakeDB) DialectReturnsOnCall(i int, result1 db.Dialect) {
+	fake.dialectMutex.Lock()
+	defer fake.dialectMutex.Unlock()
+	fake.DialectStub = nil
+	if fake.dialectReturnsOnCall == nil {
+		fake.dialectReturnsOnCall = make(map[int]struct {
+			result1 db.Dialect
+		})
+	}
+	fake.dialectReturnsOnCall[i] = struct {
+		result1 db.Dialect
+	}{result1}
+}
+
 func (fake *FakeDB) NamedExecContext(arg1 context.Context, arg2 string, arg3 interface{}) (sql.Result, error) {
 	fake.namedExecContextMutex.Lock()
 	ret, specificReturn := fake.namedExecContextReturnsOnCall[len(fake.namedExecContextArgsForCall)]
@@ -179,6 +321,10 @@ func (fake *FakeDB) NamedQueryContextReturnsOnCall(i int, result1 db.Rows, resul
 func (fake *FakeDB) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.beginTxxMutex.RLock()
+	defer fake.beginTxxMutex.RUnlock()
+	fake.dialectMutex.RLock()
+	defer fake.dialectMutex.RUnlock()
 	fake.namedExecContextMutex.RLock()
 	defer fake.namedExecContextMutex.RUnlock()
 	fake.namedQueryContextMutex.RLock()
@@ -8,6 +8,38 @@ import (
 )
 
 type FakeRows struct {
+	CloseStub        func() error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+	}
+	closeReturns struct {
+		result1 error
+	}
+	closeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ColumnTypesStub        func() ([]db.ColumnType, error)
+	columnTypesMutex       sync.RWMutex
+	columnTypesArgsForCall []struct {
+	}
+	columnTypesReturns struct {
+		result1 []db.ColumnType
+		result2 error
+	}
+	columnTypesReturnsOnCall map[int]struct {
+		result1 []db.ColumnType
+		result2 error
+	}
+	ErrStub        func() error
+	errMutex       sync.RWMutex
+	errArgsForCall []struct {
+	}
+	errReturns struct {
+		result1 error
+	}
+	errReturnsOnCall map[int]struct {
+		result1 error
+	}
 	MapScanStub        func(map[string]interface{}) error
 	mapScanMutex       sync.RWMutex
 	mapScanArgsForCall []struct {
@@ -29,10 +61,184 @@ type FakeRows struct {
 	nextReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	SliceScanStub        func() ([]interface{}, error)
+	sliceScanMutex       sync.RWMutex
+	sliceScanArgsForCall []struct {
+	}
+	sliceScanReturns struct {
+		result1 []interface{}
+		result2 error
+	}
+	sliceScanReturnsOnCall map[int]struct {
+		result1 []interface{}
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeRows) Close() error {
+	fake.closeMutex.Lock()
+	ret, specificReturn := fake.closeReturnsOnCall[len(fake.closeArgsForCall)]
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+	}{})
+	stub := fake.CloseStub
+	fakeReturns := fake.closeReturns
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRows) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeRows) CloseCalls(stub func() error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = stub
+}
+
+func (fake *FakeRows) CloseReturns(result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRows) CloseReturnsOnCall(i int, result1 error) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = nil
+	if fake.closeReturnsOnCall == nil {
+		fake.closeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.closeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRows) ColumnTypes() ([]db.ColumnType, error) {
+	fake.columnTypesMutex.Lock()
+	ret, specificReturn := fake.columnTypesReturnsOnCall[len(fake.columnTypesArgsForCall)]
+	fake.columnTypesArgsForCall = append(fake.columnTypesArgsForCall, struct {
+	}{})
+	stub := fake.ColumnTypesStub
+	fakeReturns := fake.columnTypesReturns
+	fake.recordInvocation("ColumnTypes", []interface{}{})
+	fake.columnTypesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRows) ColumnTypesCallCount() int {
+	fake.columnTypesMutex.RLock()
+	defer fake.columnTypesMutex.RUnlock()
+	return len(fake.columnTypesArgsForCall)
+}
+
+func (fake *FakeRows) ColumnTypesCalls(stub func() ([]db.ColumnType, error)) {
+	fake.columnTypesMutex.Lock()
+	defer fake.columnTypesMutex.Unlock()
+	fake.ColumnTypesStub = stub
+}
+
+func (fake *FakeRows) ColumnTypesReturns(result1 []db.ColumnType, result2 error) {
+	fake.columnTypesMutex.Lock()
+	defer fake.columnTypesMutex.Unlock()
+	fake.ColumnTypesStub = nil
+	fake.columnTypesReturns = struct {
+		result1 []db.ColumnType
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRows) ColumnTypesReturnsOnCall(i int, result1 []db.ColumnType, result2 error) {
+	fake.columnTypesMutex.Lock()
+	defer fake.columnTypesMutex.Unlock()
+	fake.ColumnTypesStub = nil
+	if fake.columnTypesReturnsOnCall == nil {
+		fake.columnTypesReturnsOnCall = make(map[int]struct {
+			result1 []db.ColumnType
+			result2 error
+		})
+	}
+	fake.columnTypesReturnsOnCall[i] = struct {
+		result1 []db.ColumnType
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRows) Err() error {
+	fake.errMutex.Lock()
+	ret, specificReturn := fake.errReturnsOnCall[len(fake.errArgsForCall)]
+	fake.errArgsForCall = append(fake.errArgsForCall, struct {
+	}{})
+	stub := fake.ErrStub
+	fakeReturns := fake.errReturns
+	fake.recordInvocation("Err", []interface{}{})
+	fake.errMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRows) ErrCallCount() int {
+	fake.errMutex.RLock()
+	defer fake.errMutex.RUnlock()
+	return len(fake.errArgsForCall)
+}
+
+func (fake *FakeRows) ErrCalls(stub func() error) {
+	fake.errMutex.Lock()
+	defer fake.errMutex.Unlock()
+	fake.ErrStub = stub
+}
+
+func (fake *FakeRows) ErrReturns(result1 error) {
+	fake.errMutex.Lock()
+	defer fake.errMutex.Unlock()
+	fake.ErrStub = nil
+	fake.errReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRows) ErrReturnsOnCall(i int, result1 error) {
+	fake.errMutex.Lock()
+	defer fake.errMutex.Unlock()
+	fake.ErrStub = nil
+	if fake.errReturnsOnCall == nil {
+		fake.errReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.errReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeRows) MapScan(arg1 map[string]interface{}) error {
 	fake.mapScanMutex.Lock()
 	ret, specificReturn := fake.mapScanReturnsOnCall[len(fake.mapScanArgsForCall)]
@@ -147,13 +353,77 @@ func (fake *FakeRows) NextReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *FakeRows) SliceScan() ([]interface{}, error) {
+	fake.sliceScanMutex.Lock()
+	ret, specificReturn := fake.sliceScanReturnsOnCall[len(fake.sliceScanArgsForCall)]
+	fake.sliceScanArgsForCall = append(fake.sliceScanArgsForCall, struct {
+	}{})
+	stub := fake.SliceScanStub
+	fakeReturns := fake.sliceScanReturns
+	fake.recordInvocation("SliceScan", []interface{}{})
+	fake.sliceScanMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRows) SliceScanCallCount() int {
+	fake.sliceScanMutex.RLock()
+	defer fake.sliceScanMutex.RUnlock()
+	return len(fake.sliceScanArgsForCall)
+}
+
+func (fake *FakeRows) SliceScanCalls(stub func() ([]interface{}, error)) {
+	fake.sliceScanMutex.Lock()
+	defer fake.sliceScanMutex.Unlock()
+	fake.SliceScanStub = stub
+}
+
+func (fake *FakeRows) SliceScanReturns(result1 []interface{}, result2 error) {
+	fake.sliceScanMutex.Lock()
+	defer fake.sliceScanMutex.Unlock()
+	fake.SliceScanStub = nil
+	fake.sliceScanReturns = struct {
+		result1 []interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRows) SliceScanReturnsOnCall(i int, result1 []interface{}, result2 error) {
+	fake.sliceScanMutex.Lock()
+	defer fake.sliceScanMutex.Unlock()
+	fake.SliceScanStub = nil
+	if fake.sliceScanReturnsOnCall == nil {
+		fake.sliceScanReturnsOnCall = make(map[int]struct {
+			result1 []interface{}
+			result2 error
+		})
+	}
+	fake.sliceScanReturnsOnCall[i] = struct {
+		result1 []interface{}
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeRows) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	fake.columnTypesMutex.RLock()
+	defer fake.columnTypesMutex.RUnlock()
+	fake.errMutex.RLock()
+	defer fake.errMutex.RUnlock()
 	fake.mapScanMutex.RLock()
 	defer fake.mapScanMutex.RUnlock()
 	fake.nextMutex.RLock()
 	defer fake.nextMutex.RUnlock()
+	fake.sliceScanMutex.RLock()
+	defer fake.sliceScanMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
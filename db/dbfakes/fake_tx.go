@@ -0,0 +1,335 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package dbfakes
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/at-silva/ddapi/db"
+)
+
+type FakeTx struct {
+	CommitStub        func() error
+	commitMutex       sync.RWMutex
+	commitArgsForCall []struct {
+	}
+	commitReturns struct {
+		result1 error
+	}
+	commitReturnsOnCall map[int]struct {
+		result1 error
+	}
+	NamedExecContextStub        func(context.Context, string, interface{}) (sql.Result, error)
+	namedExecContextMutex       sync.RWMutex
+	namedExecContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 interface{}
+	}
+	namedExecContextReturns struct {
+		result1 sql.Result
+		result2 error
+	}
+	namedExecContextReturnsOnCall map[int]struct {
+		result1 sql.Result
+		result2 error
+	}
+	NamedQueryContextStub        func(context.Context, string, interface{}) (db.Rows, error)
+	namedQueryContextMutex       sync.RWMutex
+	namedQueryContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 interface{}
+	}
+	namedQueryContextReturns struct {
+		result1 db.Rows
+		result2 error
+	}
+	namedQueryContextReturnsOnCall map[int]struct {
+		result1 db.Rows
+		result2 error
+	}
+	RollbackStub        func() error
+	rollbackMutex       sync.RWMutex
+	rollbackArgsForCall []struct {
+	}
+	rollbackReturns struct {
+		result1 error
+	}
+	rollbackReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeTx) Commit() error {
+	fake.commitMutex.Lock()
+	ret, specificReturn := fake.commitReturnsOnCall[len(fake.commitArgsForCall)]
+	fake.commitArgsForCall = append(fake.commitArgsForCall, struct {
+	}{})
+	stub := fake.CommitStub
+	fakeReturns := fake.commitReturns
+	fake.recordInvocation("Commit", []interface{}{})
+	fake.commitMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTx) CommitCallCount() int {
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
+	return len(fake.commitArgsForCall)
+}
+
+func (fake *FakeTx) CommitCalls(stub func() error) {
+	fake.commitMutex.Lock()
+	defer fake.commitMutex.Unlock()
+	fake.CommitStub = stub
+}
+
+func (fake *FakeTx) CommitReturns(result1 error) {
+	fake.commitMutex.Lock()
+	defer fake.commitMutex.Unlock()
+	fake.CommitStub = nil
+	fake.commitReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTx) CommitReturnsOnCall(i int, result1 error) {
+	fake.commitMutex.Lock()
+	defer fake.commitMutex.Unlock()
+	fake.CommitStub = nil
+	if fake.commitReturnsOnCall == nil {
+		fake.commitReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.commitReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTx) NamedExecContext(arg1 context.Context, arg2 string, arg3 interface{}) (sql.Result, error) {
+	fake.namedExecContextMutex.Lock()
+	ret, specificReturn := fake.namedExecContextReturnsOnCall[len(fake.namedExecContextArgsForCall)]
+	fake.namedExecContextArgsForCall = append(fake.namedExecContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.NamedExecContextStub
+	fakeReturns := fake.namedExecContextReturns
+	fake.recordInvocation("NamedExecContext", []interface{}{arg1, arg2, arg3})
+	fake.namedExecContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeTx) NamedExecContextCallCount() int {
+	fake.namedExecContextMutex.RLock()
+	defer fake.namedExecContextMutex.RUnlock()
+	return len(fake.namedExecContextArgsForCall)
+}
+
+func (fake *FakeTx) NamedExecContextCalls(stub func(context.Context, string, interface{}) (sql.Result, error)) {
+	fake.namedExecContextMutex.Lock()
+	defer fake.namedExecContextMutex.Unlock()
+	fake.NamedExecContextStub = stub
+}
+
+func (fake *FakeTx) NamedExecContextArgsForCall(i int) (context.Context, string, interface{}) {
+	fake.namedExecContextMutex.RLock()
+	defer fake.namedExecContextMutex.RUnlock()
+	argsForCall := fake.namedExecContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeTx) NamedExecContextReturns(result1 sql.Result, result2 error) {
+	fake.namedExecContextMutex.Lock()
+	defer fake.namedExecContextMutex.Unlock()
+	fake.NamedExecContextStub = nil
+	fake.namedExecContextReturns = struct {
+		result1 sql.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTx) NamedExecContextReturnsOnCall(i int, result1 sql.Result, result2 error) {
+	fake.namedExecContextMutex.Lock()
+	defer fake.namedExecContextMutex.Unlock()
+	fake.NamedExecContextStub = nil
+	if fake.namedExecContextReturnsOnCall == nil {
+		fake.namedExecContextReturnsOnCall = make(map[int]struct {
+			result1 sql.Result
+			result2 error
+		})
+	}
+	fake.namedExecContextReturnsOnCall[i] = struct {
+		result1 sql.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTx) NamedQueryContext(arg1 context.Context, arg2 string, arg3 interface{}) (db.Rows, error) {
+	fake.namedQueryContextMutex.Lock()
+	ret, specificReturn := fake.namedQueryContextReturnsOnCall[len(fake.namedQueryContextArgsForCall)]
+	fake.namedQueryContextArgsForCall = append(fake.namedQueryContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.NamedQueryContextStub
+	fakeReturns := fake.namedQueryContextReturns
+	fake.recordInvocation("NamedQueryContext", []interface{}{arg1, arg2, arg3})
+	fake.namedQueryContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeTx) NamedQueryContextCallCount() int {
+	fake.namedQueryContextMutex.RLock()
+	defer fake.namedQueryContextMutex.RUnlock()
+	return len(fake.namedQueryContextArgsForCall)
+}
+
+func (fake *FakeTx) NamedQueryContextCalls(stub func(context.Context, string, interface{}) (db.Rows, error)) {
+	fake.namedQueryContextMutex.Lock()
+	defer fake.namedQueryContextMutex.Unlock()
+	fake.NamedQueryContextStub = stub
+}
+
+func (fake *FakeTx) NamedQueryContextArgsForCall(i int) (context.Context, string, interface{}) {
+	fake.namedQueryContextMutex.RLock()
+	defer fake.namedQueryContextMutex.RUnlock()
+	argsForCall := fake.namedQueryContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeTx) NamedQueryContextReturns(result1 db.Rows, result2 error) {
+	fake.namedQueryContextMutex.Lock()
+	defer fake.namedQueryContextMutex.Unlock()
+	fake.NamedQueryContextStub = nil
+	fake.namedQueryContextReturns = struct {
+		result1 db.Rows
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTx) NamedQueryContextReturnsOnCall(i int, result1 db.Rows, result2 error) {
+	fake.namedQueryContextMutex.Lock()
+	defer fake.namedQueryContextMutex.Unlock()
+	fake.NamedQueryContextStub = nil
+	if fake.namedQueryContextReturnsOnCall == nil {
+		fake.namedQueryContextReturnsOnCall = make(map[int]struct {
+			result1 db.Rows
+			result2 error
+		})
+	}
+	fake.namedQueryContextReturnsOnCall[i] = struct {
+		result1 db.Rows
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeTx) Rollback() error {
+	fake.rollbackMutex.Lock()
+	ret, specificReturn := fake.rollbackReturnsOnCall[len(fake.rollbackArgsForCall)]
+	fake.rollbackArgsForCall = append(fake.rollbackArgsForCall, struct {
+	}{})
+	stub := fake.RollbackStub
+	fakeReturns := fake.rollbackReturns
+	fake.recordInvocation("Rollback", []interface{}{})
+	fake.rollbackMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTx) RollbackCallCount() int {
+	fake.rollbackMutex.RLock()
+	defer fake.rollbackMutex.RUnlock()
+	return len(fake.rollbackArgsForCall)
+}
+
+func (fake *FakeTx) RollbackCalls(stub func() error) {
+	fake.rollbackMutex.Lock()
+	defer fake.rollbackMutex.Unlock()
+	fake.RollbackStub = stub
+}
+
+func (fake *FakeTx) RollbackReturns(result1 error) {
+	fake.rollbackMutex.Lock()
+	defer fake.rollbackMutex.Unlock()
+	fake.RollbackStub = nil
+	fake.rollbackReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTx) RollbackReturnsOnCall(i int, result1 error) {
+	fake.rollbackMutex.Lock()
+	defer fake.rollbackMutex.Unlock()
+	fake.RollbackStub = nil
+	if fake.rollbackReturnsOnCall == nil {
+		fake.rollbackReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rollbackReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTx) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
+	fake.namedExecContextMutex.RLock()
+	defer fake.namedExecContextMutex.RUnlock()
+	fake.namedQueryContextMutex.RLock()
+	defer fake.namedQueryContextMutex.RUnlock()
+	fake.rollbackMutex.RLock()
+	defer fake.rollbackMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeTx) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ db.Tx = new(FakeTx)
@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Dialects
+const (
+	Unknown   Dialect = ""
+	Postgres  Dialect = "postgres"
+	MySQL     Dialect = "mysql"
+	SQLite    Dialect = "sqlite"
+	SQLServer Dialect = "sqlserver"
+)
+
+type (
+	// Dialect the SQL dialect spoken by a database
+	Dialect string
+
+	// Config the settings of a database opened with Open, the driver must be registered by the application,
+	// e.g. import _ "github.com/lib/pq"
+	Config struct {
+		// Driver the database/sql driver name, inferred from the DSN scheme when empty
+		Driver string
+		// DSN a postgres://, postgresql://, mysql://, sqlite://, sqlite3:// or sqlserver:// URL, or a DSN
+		// understood by Driver
+		DSN string
+		// MaxOpenConns the maximum number of open connections, zero means no limit
+		MaxOpenConns int
+		// MaxIdleConns the maximum number of idle connections, zero means the database/sql default
+		MaxIdleConns int
+		// ConnMaxLifetime the maximum amount of time a connection may be reused, zero means forever
+		ConnMaxLifetime time.Duration
+		// ConnMaxIdleTime the maximum amount of time a connection may be idle, zero means forever
+		ConnMaxIdleTime time.Duration
+		// ConnectTimeout bounds the dial of the driver, when it supports one, and the health ping made by Open
+		ConnectTimeout time.Duration
+	}
+)
+
+// DialectOf returns the dialect of a database/sql driver
+func DialectOf(driverName string) Dialect {
+	switch driverName {
+	case "postgres", "pgx", "pq-timeouts", "cloudsqlpostgres":
+		return Postgres
+	case "mysql":
+		return MySQL
+	case "sqlite3", "sqlite":
+		return SQLite
+	case "sqlserver", "mssql":
+		return SQLServer
+	}
+
+	return Unknown
+}
+
+// LastInsertID tells whether the drivers of the dialect support sql.Result.LastInsertId, Postgres and SQL Server
+// drivers don't, unknown dialects are assumed to
+func (d Dialect) LastInsertID() bool {
+	return d != Postgres && d != SQLServer
+}
+
+// Returning tells whether the dialect supports RETURNING clauses, unknown dialects are assumed to
+func (d Dialect) Returning() bool {
+	return d != SQLServer
+}
+
+// Output tells whether the dialect supports OUTPUT clauses, unknown dialects are assumed to
+func (d Dialect) Output() bool {
+	return d == SQLServer || d == Unknown
+}
+
+// Open opens the database described by c, sets its pool limits and pings it before returning it
+func Open(c Config) (DB, error) {
+	driver, dsn, err := c.Source()
+	if err != nil {
+		return nil, err
+	}
+
+	sdb, err := sqlx.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not open database: %w", err)
+	}
+
+	sdb.SetMaxOpenConns(c.MaxOpenConns)
+	if c.MaxIdleConns != 0 {
+		sdb.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	sdb.SetConnMaxLifetime(c.ConnMaxLifetime)
+	sdb.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+
+	ctx := context.Background()
+	if c.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.ConnectTimeout)
+		defer cancel()
+	}
+
+	err = sdb.PingContext(ctx)
+	if err != nil {
+		_ = sdb.Close()
+		return nil, fmt.Errorf("could not ping database: %w", err)
+	}
+
+	return New(sdb), nil
+}
+
+// Source returns the driver name and the driver specific DSN of c, the connect timeout is added to the DSNs
+// of the drivers supporting one unless it is already set
+func (c Config) Source() (string, string, error) {
+	driver, dsn := c.Driver, c.DSN
+	if dsn == "" {
+		return "", "", fmt.Errorf("could not open database: empty dsn")
+	}
+
+	scheme := ""
+	if i := strings.Index(dsn, "://"); i > 0 {
+		scheme = dsn[:i]
+	}
+
+	switch scheme {
+	case "postgres", "postgresql":
+		if driver == "" {
+			driver = "postgres"
+		}
+	case "mysql":
+		dsn = mysqlDSN(strings.TrimPrefix(dsn, "mysql://"))
+		if driver == "" {
+			driver = "mysql"
+		}
+	case "sqlite", "sqlite3":
+		dsn = dsn[len(scheme)+len("://"):]
+		if driver == "" {
+			driver = "sqlite3"
+		}
+	case "sqlserver":
+		if driver == "" {
+			driver = "sqlserver"
+		}
+	}
+
+	if driver == "" {
+		return "", "", fmt.Errorf("could not open database: unknown driver for dsn scheme %q", scheme)
+	}
+
+	if c.ConnectTimeout <= 0 {
+		return driver, dsn, nil
+	}
+
+	seconds := strconv.Itoa(int(math.Ceil(c.ConnectTimeout.Seconds())))
+	switch DialectOf(driver) {
+	case Postgres:
+		if strings.Contains(dsn, "://") {
+			dsn = withQueryParam(dsn, "connect_timeout", seconds)
+		} else if !strings.Contains(dsn, "connect_timeout=") {
+			dsn += " connect_timeout=" + seconds
+		}
+	case MySQL:
+		dsn = withQueryParam(dsn, "timeout", c.ConnectTimeout.String())
+	case SQLServer:
+		dsn = withQueryParam(dsn, "dial timeout", seconds)
+	}
+
+	return driver, dsn, nil
+}
+
+// mysqlDSN turns user:pass@host:3306/db into the user:pass@tcp(host:3306)/db form of the mysql driver,
+// DSNs already naming a protocol are left as they are
+func mysqlDSN(dsn string) string {
+	at := strings.LastIndex(dsn, "@")
+	rest := dsn[at+1:]
+	slash := strings.Index(rest, "/")
+	if slash < 0 {
+		slash = len(rest)
+	}
+
+	host := rest[:slash]
+	if host == "" || strings.Contains(host, "(") {
+		return dsn
+	}
+
+	return dsn[:at+1] + "tcp(" + host + ")" + rest[slash:]
+}
+
+// withQueryParam adds the k query param to the dsn unless it is already set
+func withQueryParam(dsn, k, v string) string {
+	sep := "?"
+	if i := strings.Index(dsn, "?"); i >= 0 {
+		q, err := url.ParseQuery(dsn[i+1:])
+		if err == nil && q.Get(k) != "" {
+			return dsn
+		}
+		sep = "&"
+	}
+
+	return dsn + sep + url.QueryEscape(k) + "=" + url.QueryEscape(v)
+}
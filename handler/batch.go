@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/db"
+	"github.com/at-silva/ddapi/session"
+	"github.com/at-silva/ddapi/statement"
+)
+
+type (
+	batchHandler struct {
+		db db.DB
+		sc check.SignatureChecker
+		s  session.Reader
+		pc check.ParamsChecker
+		o  options
+	}
+
+	batchRequest struct {
+		Statements []request `json:"statements"`
+	}
+
+	batchResult struct {
+		RowsAffected   int64       `json:"rowsAffected"`
+		LastInsertedID *int64      `json:"lastInsertedId,omitempty"`
+		Rows           interface{} `json:"rows,omitempty"`
+	}
+
+	batchResponse struct {
+		Results []batchResult `json:"results"`
+		Error   *string       `json:"error"`
+	}
+)
+
+// NewBatch returns a new DDApi batch handler, executing several signed exec statements in a single transaction.
+// Params can reference the outputs of earlier statements, e.g. {"order_id": {"$ref": "0.lastInsertedId"}}
+func NewBatch(db db.DB, sc check.SignatureChecker, s session.Reader, pc check.ParamsChecker, opts ...Option) http.Handler {
+	return batchHandler{db, sc, s, pc, newOptions(opts)}
+}
+
+func batchError(err error) string {
+	e := err.Error()
+	resp, _ := json.Marshal(batchResponse{Error: &e})
+	return string(resp)
+}
+
+func (h batchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, batchError(fmt.Errorf("could not read body: %w", err)), http.StatusInternalServerError)
+		return
+	}
+
+	var br batchRequest
+	err = json.Unmarshal(b, &br)
+	if err != nil {
+		http.Error(w, batchError(fmt.Errorf("could not unmarshal body: %w", err)), http.StatusBadRequest)
+		return
+	}
+
+	if len(br.Statements) == 0 {
+		http.Error(w, batchError(fmt.Errorf("could not execute batch: no statements")), http.StatusBadRequest)
+		return
+	}
+
+	params := make([]map[string]interface{}, len(br.Statements))
+	for i := range br.Statements {
+		var code int
+		params[i], code, err = h.prepare(r, &br.Statements[i])
+		if err != nil {
+			http.Error(w, batchError(fmt.Errorf("statement %d: %w", i, err)), code)
+			return
+		}
+	}
+
+	claims := map[string]interface{}{}
+	code, err := readSession(h.s, r, claims)
+	if err != nil {
+		http.Error(w, batchError(err), code)
+		return
+	}
+
+	tx, err := h.db.BeginTxx(r.Context(), h.o.txOptions)
+	if err != nil {
+		http.Error(w, batchError(fmt.Errorf("could not begin transaction: %w", err)), http.StatusInternalServerError)
+		return
+	}
+
+	res := make([]batchResult, 0, len(br.Statements))
+	for i, q := range br.Statements {
+		code, err = h.exec(r, tx, q, params[i], claims, &res)
+		if err != nil {
+			rollback(tx)
+			http.Error(w, batchError(fmt.Errorf("statement %d: %w", i, err)), code)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, batchError(fmt.Errorf("could not commit transaction: %w", err)), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(batchResponse{res, nil})
+	if err != nil {
+		log.Printf("marshal failed: %v", err)
+		return
+	}
+
+	_, err = w.Write(resp)
+	if err != nil {
+		log.Printf("write failed: %v", err)
+	}
+}
+
+// prepare decodes a statement and checks its signature and kind before anything is executed
+func (h batchHandler) prepare(r *http.Request, q *request) (map[string]interface{}, int, error) {
+	code, err := decodeStatement(r.Context(), q, h.o)
+	if err != nil {
+		return nil, code, err
+	}
+
+	code, err = checkSignatures(h.sc, h.o, *q)
+	if err != nil {
+		return nil, code, err
+	}
+
+	err = checkKind(statement.Exec, *q)
+	if err != nil {
+		return nil, http.StatusForbidden, err
+	}
+
+	_, _, err = expectedRows(*q)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	p := map[string]interface{}{}
+	err = json.Unmarshal([]byte(q.Params), &p)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("could not unmarshal params: %w", err)
+	}
+
+	return p, http.StatusOK, nil
+}
+
+// exec resolves the references to earlier results, copies the session params, checks the params and executes the statement
+func (h batchHandler) exec(r *http.Request, tx db.Tx, q request, p, claims map[string]interface{}, res *[]batchResult) (int, error) {
+	err := resolveRefs(p, *res)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	for k, v := range claims {
+		p[k] = v
+	}
+
+	err = h.pc.Check(p, q.ParamsSchema)
+	if err != nil {
+		return http.StatusForbidden, fmt.Errorf("invalid params: %w", err)
+	}
+
+	er, err := execStatement(r.Context(), tx, h.db.Dialect(), q, p, h.o.encoders)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	err = checkRowsAffected(q, er)
+	if err != nil {
+		return http.StatusConflict, err
+	}
+
+	*res = append(*res, batchResult{er.RowsAffected, er.LastInsertedID, er.Rows})
+	return http.StatusOK, nil
+}
+
+// resolveRefs replaces the {"$ref": "<index>.<output>"} params with the outputs of earlier statements, outputs
+// being lastInsertedId, rowsAffected or rows.<row>.<column> for statements with a RETURNING clause
+func resolveRefs(p map[string]interface{}, res []batchResult) error {
+	for k, v := range p {
+		m, ok := v.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			continue
+		}
+
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			continue
+		}
+
+		parts := strings.SplitN(ref, ".", 2)
+		i, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 || i < 0 || i >= len(res) {
+			return fmt.Errorf("could not resolve param %s: invalid reference %q", k, ref)
+		}
+
+		p[k], err = output(res[i], parts[1])
+		if err != nil {
+			return fmt.Errorf("could not resolve param %s: %w", k, err)
+		}
+	}
+
+	return nil
+}
+
+// output returns the named output of a statement result, failing when the statement didn't produce it
+func output(r batchResult, name string) (interface{}, error) {
+	switch {
+	case name == "lastInsertedId":
+		if r.LastInsertedID == nil {
+			return nil, fmt.Errorf("no lastInsertedId, the driver doesn't support it, use a RETURNING clause and a rows reference instead")
+		}
+		return *r.LastInsertedID, nil
+	case name == "rowsAffected":
+		return r.RowsAffected, nil
+	case strings.HasPrefix(name, "rows."):
+		parts := strings.SplitN(name, ".", 3)
+		rows, _ := r.Rows.([]interface{})
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || len(parts) != 3 || n < 0 || n >= len(rows) {
+			return nil, fmt.Errorf("no row %q", name)
+		}
+
+		row, ok := rows[n].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("no row %q", name)
+		}
+
+		v, ok := row[parts[2]]
+		if !ok {
+			return nil, fmt.Errorf("no column %q", name)
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unknown output %q", name)
+	}
+}
+
+func rollback(tx db.Tx) {
+	err := tx.Rollback()
+	if err != nil {
+		log.Printf("rollback failed: %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/at-silva/ddapi/check/checkfakes"
+	"github.com/at-silva/ddapi/db/dbfakes"
+	"github.com/at-silva/ddapi/session/sessionfakes"
+	"github.com/at-silva/ddapi/statement"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("batchHandler", func() {
+
+	var (
+		fakeDB               *dbfakes.FakeDB
+		fakeTx               *dbfakes.FakeTx
+		fakeResult           *dbfakes.FakeResult
+		fakeSignatureChecker *checkfakes.FakeSignatureChecker
+		fakeSessionReader    *sessionfakes.FakeReader
+		fakeParamsChecker    *checkfakes.FakeParamsChecker
+
+		recorder *httptest.ResponseRecorder
+		ehandler http.Handler
+
+		encode func(k statement.Kind, sql string) string
+		post   func(body string)
+	)
+
+	BeforeEach(func() {
+		fakeDB = new(dbfakes.FakeDB)
+		fakeTx = new(dbfakes.FakeTx)
+		fakeResult = new(dbfakes.FakeResult)
+		fakeSignatureChecker = new(checkfakes.FakeSignatureChecker)
+		fakeSessionReader = new(sessionfakes.FakeReader)
+		fakeParamsChecker = new(checkfakes.FakeParamsChecker)
+
+		fakeDB.BeginTxxReturns(fakeTx, nil)
+		fakeTx.NamedExecContextReturns(fakeResult, nil)
+		fakeResult.LastInsertIdReturns(42, nil)
+		fakeResult.RowsAffectedReturns(1, nil)
+		fakeSessionReader.CopyStub = func(_ string, p map[string]interface{}) error {
+			p["user_id"] = 7
+			return nil
+		}
+
+		recorder = httptest.NewRecorder()
+		ehandler = NewBatch(fakeDB, fakeSignatureChecker, fakeSessionReader, fakeParamsChecker)
+
+		encode = func(k statement.Kind, sql string) string {
+			e, err := statement.Encode(statement.Statement{Kind: k, SQL: sql, ParamsSchema: []byte(`{}`)})
+			Expect(err).ShouldNot(HaveOccurred())
+			return e
+		}
+
+		post = func(body string) {
+			request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/batch", bytes.NewBufferString(body))
+			Expect(err).ShouldNot(HaveOccurred())
+			request.Header.Set("Authorization", "Bearer token")
+
+			ehandler.ServeHTTP(recorder, request)
+		}
+	})
+
+	It("should execute every statement in a single transaction", func() {
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "insert into orders(user_id) values(:user_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{}},
+			{"statement":"` + encode(statement.Exec, "insert into order_item(order_id, product_id) values(:order_id, :product_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{"order_id":{"$ref":"0.lastInsertedId"},"product_id":3}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK), recorder.Body.String())
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": [
+				{"rowsAffected": 1, "lastInsertedId": 42},
+				{"rowsAffected": 1, "lastInsertedId": 42}
+			],
+			"error": null
+		}`))
+
+		Expect(fakeDB.BeginTxxCallCount()).Should(Equal(1))
+		Expect(fakeSessionReader.CopyCallCount()).Should(Equal(1))
+		Expect(fakeTx.NamedExecContextCallCount()).Should(Equal(2))
+		_, sql, p := fakeTx.NamedExecContextArgsForCall(1)
+		Expect(sql).Should(Equal("insert into order_item(order_id, product_id) values(:order_id, :product_id)"))
+		Expect(p).Should(Equal(map[string]interface{}{"order_id": int64(42), "product_id": float64(3), "user_id": 7}))
+		Expect(fakeTx.CommitCallCount()).Should(Equal(1))
+		Expect(fakeTx.RollbackCallCount()).Should(BeZero())
+	})
+
+	It("should not override the session params", func() {
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "delete from cart where user_id = :user_id") + `","statementSignature":"c2lnbmF0dXJl","params":{"user_id":1}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK), recorder.Body.String())
+		_, _, p := fakeTx.NamedExecContextArgsForCall(0)
+		Expect(p).Should(Equal(map[string]interface{}{"user_id": 7}))
+	})
+
+	It("should roll back the transaction when a statement fails", func() {
+		fakeTx.NamedExecContextReturnsOnCall(1, nil, errors.New("constraint violation"))
+
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "insert into orders(user_id) values(:user_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{}},
+			{"statement":"` + encode(statement.Exec, "insert into order_item(order_id) values(:order_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{"order_id":{"$ref":"0.lastInsertedId"}}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": null,
+			"error": "statement 1: could not query the database: constraint violation"
+		}`))
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+		Expect(fakeTx.CommitCallCount()).Should(BeZero())
+	})
+
+	It("should roll back the transaction when a reference is invalid", func() {
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "insert into orders(user_id) values(:user_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{}},
+			{"statement":"` + encode(statement.Exec, "insert into order_item(order_id) values(:order_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{"order_id":{"$ref":"1.lastInsertedId"}}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": null,
+			"error": "statement 1: could not resolve param order_id: invalid reference \"1.lastInsertedId\""
+		}`))
+		Expect(fakeTx.NamedExecContextCallCount()).Should(Equal(1))
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+	})
+
+	It("should roll back the transaction when the driver doesn't support the referenced lastInsertedId", func() {
+		fakeResult.LastInsertIdReturns(0, errors.New("LastInsertId is not supported by this driver"))
+
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "insert into orders(user_id) values(:user_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{}},
+			{"statement":"` + encode(statement.Exec, "insert into order_item(order_id) values(:order_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{"order_id":{"$ref":"0.lastInsertedId"}}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": null,
+			"error": "statement 1: could not resolve param order_id: no lastInsertedId, the driver doesn't support it, use a RETURNING clause and a rows reference instead"
+		}`))
+		Expect(fakeTx.NamedExecContextCallCount()).Should(Equal(1))
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+		Expect(fakeTx.CommitCallCount()).Should(BeZero())
+	})
+
+	It("should resolve references to the rows returned by earlier statements", func() {
+		fakeRows := new(dbfakes.FakeRows)
+		fakeRows.NextReturnsOnCall(0, true)
+		fakeRows.MapScanStub = func(m map[string]interface{}) error {
+			m["id"] = int64(9)
+			return nil
+		}
+		fakeTx.NamedQueryContextReturns(fakeRows, nil)
+		fakeResult.LastInsertIdReturns(0, errors.New("LastInsertId is not supported by this driver"))
+
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "insert into orders(user_id) values(:user_id) returning id") + `","statementSignature":"c2lnbmF0dXJl","params":{}},
+			{"statement":"` + encode(statement.Exec, "insert into order_item(order_id) values(:order_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{"order_id":{"$ref":"0.rows.0.id"}}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK), recorder.Body.String())
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": [
+				{"rowsAffected": 1, "rows": [{"id": 9}]},
+				{"rowsAffected": 1}
+			],
+			"error": null
+		}`))
+		_, _, p := fakeTx.NamedExecContextArgsForCall(0)
+		Expect(p).Should(Equal(map[string]interface{}{"order_id": int64(9), "user_id": 7}))
+		Expect(fakeTx.CommitCallCount()).Should(Equal(1))
+	})
+
+	It("should roll back the transaction when a referenced row doesn't exist", func() {
+		fakeTx.NamedQueryContextReturns(new(dbfakes.FakeRows), nil)
+
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "insert into orders(user_id) values(:user_id) returning id") + `","statementSignature":"c2lnbmF0dXJl","params":{}},
+			{"statement":"` + encode(statement.Exec, "insert into order_item(order_id) values(:order_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{"order_id":{"$ref":"0.rows.0.id"}}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": null,
+			"error": "statement 1: could not resolve param order_id: no row \"rows.0.id\""
+		}`))
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+	})
+
+	It("should roll back the transaction when the params are invalid", func() {
+		fakeParamsChecker.CheckReturnsOnCall(1, errors.New("name is required"))
+
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "insert into orders(user_id) values(:user_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{}},
+			{"statement":"` + encode(statement.Exec, "insert into product(name) values(:name)") + `","statementSignature":"c2lnbmF0dXJl","params":{}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": null,
+			"error": "statement 1: invalid params: name is required"
+		}`))
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+	})
+
+	It("should check every statement before beginning the transaction", func() {
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "insert into orders(user_id) values(:user_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{}},
+			{"statement":"` + encode(statement.Query, "select * from orders") + `","statementSignature":"c2lnbmF0dXJl","params":{}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": null,
+			"error": "statement 1: could not check statement kind: expected exec, got query"
+		}`))
+		Expect(fakeDB.BeginTxxCallCount()).Should(BeZero())
+	})
+
+	It("should return Forbidden when a signature is invalid", func() {
+		fakeSignatureChecker.CheckReturnsOnCall(1, errors.New("invalid signature"))
+
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "insert into orders(user_id) values(:user_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{}},
+			{"statement":"` + encode(statement.Exec, "delete from orders") + `","statementSignature":"c2lnbmF0dXJl","params":{}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": null,
+			"error": "statement 1: could not validate statement signature: invalid signature"
+		}`))
+		Expect(fakeDB.BeginTxxCallCount()).Should(BeZero())
+	})
+
+	It("should return BadRequest when there are no statements", func() {
+		post(`{"statements":[]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": null,
+			"error": "could not execute batch: no statements"
+		}`))
+	})
+
+	It("should return InternalServerError when the transaction can't be committed", func() {
+		fakeTx.CommitReturns(errors.New("connection lost"))
+
+		post(`{"statements":[
+			{"statement":"` + encode(statement.Exec, "insert into orders(user_id) values(:user_id)") + `","statementSignature":"c2lnbmF0dXJl","params":{}}
+		]}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": null,
+			"error": "could not commit transaction: connection lost"
+		}`))
+	})
+
+})
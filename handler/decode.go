@@ -3,9 +3,13 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
+
+	"github.com/at-silva/ddapi/statement"
 )
 
 type contextKey int
@@ -17,7 +21,9 @@ const (
 )
 
 // DecodeRequest decodes an incoming request and adds it to the context
-func DecodeRequest(next http.Handler) http.Handler {
+func DecodeRequest(next http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var q request
 
@@ -33,10 +39,15 @@ func DecodeRequest(next http.Handler) http.Handler {
 			return
 		}
 
+		code, err := decodeStatement(r.Context(), &q, o)
+		if err != nil {
+			http.Error(w, errEncode(err), code)
+			return
+		}
+
 		r = r.WithContext(context.WithValue(r.Context(), DecodedRequest, q))
 
-		p := map[string]interface{}{}
-		err = json.Unmarshal([]byte(q.Params), &p)
+		p, err := decodeParams(q.Params)
 		if err != nil {
 			http.Error(w, errEncode(fmt.Errorf("could not unmarshal params: %w", err)), http.StatusBadRequest)
 			return
@@ -47,3 +58,97 @@ func DecodeRequest(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// decodeParams decodes the params object, or the array of params objects of bulk requests
+func decodeParams(s string) (interface{}, error) {
+	if !strings.HasPrefix(strings.TrimSpace(s), "[") {
+		p := map[string]interface{}{}
+		err := json.Unmarshal([]byte(s), &p)
+		return p, err
+	}
+
+	var bulk []map[string]interface{}
+	err := json.Unmarshal([]byte(s), &bulk)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bulk) == 0 {
+		return nil, fmt.Errorf("empty params")
+	}
+
+	for _, p := range bulk {
+		if p == nil {
+			return nil, fmt.Errorf("null params")
+		}
+	}
+
+	return bulk, nil
+}
+
+// decodeStatement replaces the request SQL and params schema with the ones found in its statement envelope, if any,
+// decrypting the envelope first when it is encrypted, or with the ones registered under its statement id
+func decodeStatement(ctx context.Context, q *request, o options) (int, error) {
+	if q.StatementID != "" {
+		return lookupStatement(ctx, q, o)
+	}
+
+	if q.EncryptedStatement != "" {
+		if q.Statement != "" {
+			return http.StatusBadRequest, fmt.Errorf("could not decode statement: statement and encryptedStatement are mutually exclusive")
+		}
+
+		if o.cipher == nil {
+			return http.StatusBadRequest, fmt.Errorf("could not decode statement: encrypted statements are not supported")
+		}
+
+		p, err := o.cipher.Decrypt(q.EncryptedStatement)
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("could not decode statement: %w", err)
+		}
+
+		q.Statement = string(p)
+	}
+
+	if q.Statement == "" {
+		return http.StatusOK, nil
+	}
+
+	s, err := statement.Decode(q.Statement)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	setStatement(q, s)
+	return http.StatusOK, nil
+}
+
+func lookupStatement(ctx context.Context, q *request, o options) (int, error) {
+	if q.Statement != "" || q.EncryptedStatement != "" {
+		return http.StatusBadRequest, fmt.Errorf("could not decode statement: statementId and statement are mutually exclusive")
+	}
+
+	if o.registry == nil {
+		return http.StatusBadRequest, fmt.Errorf("could not decode statement: registered statements are not supported")
+	}
+
+	s, err := o.registry.Lookup(ctx, q.StatementID)
+	if errors.Is(err, statement.ErrNotFound) {
+		return http.StatusNotFound, fmt.Errorf("could not find statement %s", q.StatementID)
+	}
+
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("could not lookup statement %s: %w", q.StatementID, err)
+	}
+
+	setStatement(q, s)
+	q.registered = true
+	return http.StatusOK, nil
+}
+
+func setStatement(q *request, s statement.Statement) {
+	q.SQL = s.SQL
+	q.ParamsSchema = string(s.ParamsSchema)
+	q.Kind = s.Kind
+	q.Meta = s.Meta
+}
@@ -2,12 +2,15 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 
 	"github.com/at-silva/ddapi/handler/handlerfakes"
+	"github.com/at-silva/ddapi/statement"
+	"github.com/at-silva/ddapi/statement/statementfakes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -58,6 +61,225 @@ var _ = Describe("DecodeRequest", func() {
 		}))
 	})
 
+	It("should decode the sql and params schema from the statement envelope", func() {
+		st, err := statement.Encode(statement.Statement{
+			Kind:         statement.Exec,
+			SQL:          "insert into product(name) values(:name)",
+			ParamsSchema: []byte(`{"type":"object","required":["name"],"properties":{"name":{"type":"string"}}}`),
+			Meta:         map[string]interface{}{"screen": "products"},
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		body := `
+		{
+			"sql": "delete from product",
+			"statement": "` + st + `",
+			"statementSignature": "valid-statement-signature",
+			"params": {"name": "Product 1"}
+		}`
+
+		r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/exec", strings.NewReader(body))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		dhandler.ServeHTTP(recorder, r)
+		_, req := fakeNext.ServeHTTPArgsForCall(0)
+
+		Expect(req.Context().Value(DecodedRequest)).Should(Equal(request{
+			SQL:                "insert into product(name) values(:name)",
+			Params:             `{"name": "Product 1"}`,
+			ParamsSchema:       `{"type":"object","required":["name"],"properties":{"name":{"type":"string"}}}`,
+			Statement:          st,
+			StatementSignature: "valid-statement-signature",
+			Kind:               statement.Exec,
+			Meta:               map[string]interface{}{"screen": "products"},
+		}))
+	})
+
+	It("should return BadRequest when it can't decode the statement envelope", func() {
+		body := `
+		{
+			"statement": "invalid statement",
+			"statementSignature": "valid-statement-signature",
+			"params": {"name": "Product 1"}
+		}`
+
+		r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/exec", strings.NewReader(body))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		dhandler.ServeHTTP(recorder, r)
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{"error":"could not decode statement: illegal base64 data at input byte 7"}`))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+	})
+
+	Context("with encrypted statements", func() {
+
+		var (
+			fakeCipher *statementfakes.FakeCipher
+			st         string
+		)
+
+		BeforeEach(func() {
+			var err error
+			st, err = statement.Encode(statement.Statement{
+				Kind:         statement.Query,
+				SQL:          "select * from product where name = :name",
+				ParamsSchema: []byte(`{"type":"object"}`),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			fakeCipher = new(statementfakes.FakeCipher)
+			fakeCipher.DecryptReturns([]byte(st), nil)
+			dhandler = DecodeRequest(fakeNext, WithStatementCipher(fakeCipher))
+		})
+
+		It("should decrypt the statement before decoding it", func() {
+			body := `{"encryptedStatement": "ciphertext", "statementSignature": "valid-statement-signature", "params": {"name": "Product 1"}}`
+
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/query", strings.NewReader(body))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dhandler.ServeHTTP(recorder, r)
+			Expect(fakeCipher.DecryptArgsForCall(0)).Should(Equal("ciphertext"))
+			_, req := fakeNext.ServeHTTPArgsForCall(0)
+
+			Expect(req.Context().Value(DecodedRequest)).Should(Equal(request{
+				SQL:                "select * from product where name = :name",
+				Params:             `{"name": "Product 1"}`,
+				ParamsSchema:       `{"type":"object"}`,
+				Statement:          st,
+				StatementSignature: "valid-statement-signature",
+				EncryptedStatement: "ciphertext",
+				Kind:               statement.Query,
+			}))
+		})
+
+		It("should return BadRequest when the statement can't be decrypted", func() {
+			fakeCipher.DecryptReturns(nil, errors.New("message authentication failed"))
+			body := `{"encryptedStatement": "ciphertext", "statementSignature": "valid-statement-signature", "params": {"name": "Product 1"}}`
+
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/query", strings.NewReader(body))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dhandler.ServeHTTP(recorder, r)
+			Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+			Expect(recorder.Body).Should(MatchJSON(`{"error":"could not decode statement: message authentication failed"}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
+
+		It("should return BadRequest when both a plain and an encrypted statement are sent", func() {
+			body := `{"statement": "` + st + `", "encryptedStatement": "ciphertext", "statementSignature": "valid-statement-signature", "params": {"name": "Product 1"}}`
+
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/query", strings.NewReader(body))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dhandler.ServeHTTP(recorder, r)
+			Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+			Expect(recorder.Body).Should(MatchJSON(`{"error":"could not decode statement: statement and encryptedStatement are mutually exclusive"}`))
+			Expect(fakeCipher.DecryptCallCount()).Should(BeZero())
+		})
+
+		It("should return BadRequest when no cipher is configured", func() {
+			dhandler = DecodeRequest(fakeNext)
+			body := `{"encryptedStatement": "ciphertext", "statementSignature": "valid-statement-signature", "params": {"name": "Product 1"}}`
+
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/query", strings.NewReader(body))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dhandler.ServeHTTP(recorder, r)
+			Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+			Expect(recorder.Body).Should(MatchJSON(`{"error":"could not decode statement: encrypted statements are not supported"}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
+	})
+
+	Context("with registered statements", func() {
+
+		var fakeRegistry *statementfakes.FakeRegistry
+
+		BeforeEach(func() {
+			fakeRegistry = new(statementfakes.FakeRegistry)
+			fakeRegistry.LookupReturns(statement.Statement{
+				Kind:         statement.Query,
+				SQL:          "select * from product where name = :name",
+				ParamsSchema: []byte(`{"type":"object"}`),
+			}, nil)
+			dhandler = DecodeRequest(fakeNext, WithRegistry(fakeRegistry))
+		})
+
+		It("should resolve the statement by its id", func() {
+			body := `{"statementId": "products/list", "sql": "delete from product", "params": {"name": "Product 1"}}`
+
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/query", strings.NewReader(body))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dhandler.ServeHTTP(recorder, r)
+			_, id := fakeRegistry.LookupArgsForCall(0)
+			Expect(id).Should(Equal("products/list"))
+			_, req := fakeNext.ServeHTTPArgsForCall(0)
+
+			Expect(req.Context().Value(DecodedRequest)).Should(Equal(request{
+				SQL:          "select * from product where name = :name",
+				Params:       `{"name": "Product 1"}`,
+				ParamsSchema: `{"type":"object"}`,
+				StatementID:  "products/list",
+				Kind:         statement.Query,
+				registered:   true,
+			}))
+		})
+
+		It("should return NotFound when the statement is not registered", func() {
+			fakeRegistry.LookupReturns(statement.Statement{}, statement.ErrNotFound)
+			body := `{"statementId": "products/delete", "params": {"name": "Product 1"}}`
+
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/query", strings.NewReader(body))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dhandler.ServeHTTP(recorder, r)
+			Expect(recorder.Code).Should(Equal(http.StatusNotFound))
+			Expect(recorder.Body).Should(MatchJSON(`{"error":"could not find statement products/delete"}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
+
+		It("should return InternalServerError when the registry fails", func() {
+			fakeRegistry.LookupReturns(statement.Statement{}, errors.New("connection refused"))
+			body := `{"statementId": "products/list", "params": {"name": "Product 1"}}`
+
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/query", strings.NewReader(body))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dhandler.ServeHTTP(recorder, r)
+			Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+			Expect(recorder.Body).Should(MatchJSON(`{"error":"could not lookup statement products/list: connection refused"}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
+
+		It("should return BadRequest when both a statement id and a statement are sent", func() {
+			body := `{"statementId": "products/list", "statement": "eyJ9", "params": {"name": "Product 1"}}`
+
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/query", strings.NewReader(body))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dhandler.ServeHTTP(recorder, r)
+			Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+			Expect(recorder.Body).Should(MatchJSON(`{"error":"could not decode statement: statementId and statement are mutually exclusive"}`))
+			Expect(fakeRegistry.LookupCallCount()).Should(BeZero())
+		})
+
+		It("should return BadRequest when no registry is configured", func() {
+			dhandler = DecodeRequest(fakeNext)
+			body := `{"statementId": "products/list", "params": {"name": "Product 1"}}`
+
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/query", strings.NewReader(body))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			dhandler.ServeHTTP(recorder, r)
+			Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+			Expect(recorder.Body).Should(MatchJSON(`{"error":"could not decode statement: registered statements are not supported"}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
+	})
+
 	It("should return InternalServerError when it can't read the body", func() {
 		ctx := context.Background()
 		fakeReader := new(handlerfakes.FakeReader)
@@ -104,4 +326,41 @@ var _ = Describe("DecodeRequest", func() {
 		Expect(recorder.Body).Should(MatchJSON(`{"error":"could not unmarshal params: json: cannot unmarshal number into Go value of type map[string]interface {}"}`))
 	})
 
+	It("should decode an array of params for bulk statements", func() {
+		body := `
+		{
+			"sql": "insert into product(name) values(:name)",
+			"sqlSignature": "valid-sql-signature",
+			"params": [{"name": "Product 1"}, {"name": "Product 2"}]
+		}`
+
+		r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/exec", strings.NewReader(body))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		dhandler.ServeHTTP(recorder, r)
+		_, req := fakeNext.ServeHTTPArgsForCall(0)
+
+		Expect(req.Context().Value(DecodedParams)).Should(Equal([]map[string]interface{}{
+			{"name": "Product 1"},
+			{"name": "Product 2"},
+		}))
+	})
+
+	It("should return BadRequest when the array of params is empty", func() {
+		body := `
+		{
+			"sql": "insert into product(name) values(:name)",
+			"sqlSignature": "valid-sql-signature",
+			"params": []
+		}`
+
+		r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/exec", strings.NewReader(body))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		dhandler.ServeHTTP(recorder, r)
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{"error":"could not unmarshal params: empty params"}`))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+	})
+
 })
@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/at-silva/ddapi/db"
+)
+
+type (
+	// Encoder encodes the values scanned from a column into their JSON (and CSV) friendly form
+	Encoder interface {
+		Encode(v interface{}) (interface{}, error)
+	}
+
+	// Encode encoder function type
+	Encode func(v interface{}) (interface{}, error)
+
+	// Encoders maps column database types, as reported by the driver (e.g. NUMERIC, BYTEA, JSONB), to their encoder
+	Encoders map[string]Encoder
+
+	// valueEncoder encodes scanned rows according to the database type of their columns, the []byte values of
+	// columns without an encoder are turned into strings
+	valueEncoder struct {
+		columns []string
+		byIndex []Encoder
+		byName  map[string]Encoder
+	}
+)
+
+// maxSafeInteger is the largest integer a JSON number can hold without losing precision in Javascript
+const maxSafeInteger = 1<<53 - 1
+
+// layouts of the dates and times drivers return as text, with and without a time zone
+var (
+	zonedLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999Z07:00"}
+	localLayouts = []string{"2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999"}
+)
+
+const (
+	// localLayout RFC 3339 without the offset, for times stored without a time zone
+	localLayout = "2006-01-02T15:04:05.999999999"
+	// dateLayout RFC 3339 full-date
+	dateLayout = "2006-01-02"
+)
+
+// Encode encodes a value
+func (f Encode) Encode(v interface{}) (interface{}, error) {
+	return f(v)
+}
+
+// DefaultEncoders returns encoders for the usual MySQL, PostgreSQL and SQLite types: decimals as exact strings,
+// binary as base64, dates and times in RFC 3339, JSON as embedded JSON and integers beyond 2^53 as strings.
+// Times stored without a time zone are written without an offset, see TimeIn to read them in a location
+func DefaultEncoders() Encoders {
+	e := Encoders{}
+	for _, t := range []string{"DECIMAL", "NUMERIC"} {
+		e[t] = Decimal(false)
+	}
+	for _, t := range []string{"BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "BYTEA"} {
+		e[t] = Encode(Binary)
+	}
+	e["DATE"] = Encode(Date)
+	for _, t := range []string{"DATETIME", "TIMESTAMP", "TIMESTAMPTZ"} {
+		e[t] = Encode(Time)
+	}
+	for _, t := range []string{"JSON", "JSONB"} {
+		e[t] = Encode(JSON)
+	}
+	for _, t := range []string{"INT", "INTEGER", "BIGINT", "UNSIGNED BIGINT", "INT8"} {
+		e[t] = Encode(Integer)
+	}
+
+	return e
+}
+
+// Decimal encodes decimals exactly, as JSON numbers when asNumber is set and as strings otherwise
+func Decimal(asNumber bool) Encode {
+	return func(v interface{}) (interface{}, error) {
+		var s string
+		switch v := v.(type) {
+		case nil:
+			return nil, nil
+		case []byte:
+			s = string(v)
+		case string:
+			s = v
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			s = fmt.Sprint(v)
+		}
+
+		if !asNumber {
+			return s, nil
+		}
+
+		if !json.Valid([]byte(s)) {
+			return nil, fmt.Errorf("could not encode decimal %q", s)
+		}
+
+		return json.Number(s), nil
+	}
+}
+
+// Binary encodes binary values as base64 strings
+func Binary(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v), nil
+	case string:
+		return base64.StdEncoding.EncodeToString([]byte(v)), nil
+	default:
+		return v, nil
+	}
+}
+
+// Time encodes times in RFC 3339, values the driver returns as text are parsed first. Text without a time zone
+// is written without an offset, rather than asserting a zone the database never stored, and dates as full-dates
+func Time(v interface{}) (interface{}, error) {
+	return TimeIn(nil)(v)
+}
+
+// TimeIn encodes times like Time, but reads the text values without a time zone in loc and writes their offset
+func TimeIn(loc *time.Location) Encode {
+	return func(v interface{}) (interface{}, error) {
+		if t, ok := v.(time.Time); ok {
+			return t.Format(time.RFC3339Nano), nil
+		}
+
+		s, ok := text(v)
+		if !ok {
+			return v, nil
+		}
+
+		for _, l := range zonedLayouts {
+			t, err := time.Parse(l, s)
+			if err == nil {
+				return t.Format(time.RFC3339Nano), nil
+			}
+		}
+
+		in, local, date := loc, localLayout, dateLayout
+		if loc == nil {
+			in = time.UTC
+		} else {
+			local, date = time.RFC3339Nano, time.RFC3339Nano
+		}
+
+		for _, l := range localLayouts {
+			t, err := time.ParseInLocation(l, s, in)
+			if err == nil {
+				return t.Format(local), nil
+			}
+		}
+
+		t, err := time.ParseInLocation(dateLayout, s, in)
+		if err == nil {
+			return t.Format(date), nil
+		}
+
+		return s, nil
+	}
+}
+
+// Date encodes dates as RFC 3339 full-dates, e.g. 2024-01-02, whatever the time of day and time zone the
+// driver gives them
+func Date(v interface{}) (interface{}, error) {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(dateLayout), nil
+	}
+
+	s, ok := text(v)
+	if !ok {
+		return v, nil
+	}
+
+	for _, ls := range [][]string{{dateLayout}, zonedLayouts, localLayouts} {
+		for _, l := range ls {
+			t, err := time.Parse(l, s)
+			if err == nil {
+				return t.Format(dateLayout), nil
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// text returns the text of the values drivers return as strings or bytes
+func text(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case []byte:
+		return string(v), true
+	case string:
+		return v, true
+	default:
+		return "", false
+	}
+}
+
+// JSON embeds JSON values as they are instead of as strings
+func JSON(v interface{}) (interface{}, error) {
+	var b []byte
+	switch v := v.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return v, nil
+	}
+
+	if !json.Valid(b) {
+		return nil, fmt.Errorf("could not encode json %q", b)
+	}
+
+	return json.RawMessage(b), nil
+}
+
+// Integer encodes integers as numbers when Javascript can hold them, and as strings beyond 2^53
+func Integer(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case int64:
+		if v > maxSafeInteger || v < -maxSafeInteger {
+			return strconv.FormatInt(v, 10), nil
+		}
+		return v, nil
+	case uint64:
+		if v > maxSafeInteger {
+			return strconv.FormatUint(v, 10), nil
+		}
+		return v, nil
+	case []byte:
+		return parseInteger(string(v))
+	case string:
+		return parseInteger(v)
+	default:
+		return v, nil
+	}
+}
+
+func parseInteger(s string) (interface{}, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		return Integer(i)
+	}
+
+	u, err := strconv.ParseUint(s, 10, 64)
+	if err == nil {
+		return Integer(u)
+	}
+
+	return s, nil
+}
+
+func newValueEncoder(e Encoders, cts []db.ColumnType) valueEncoder {
+	if len(e) == 0 {
+		return valueEncoder{}
+	}
+
+	res := valueEncoder{columnNames(cts), make([]Encoder, len(cts)), map[string]Encoder{}}
+	for i, ct := range cts {
+		enc := e[strings.ToUpper(ct.DatabaseTypeName())]
+		res.byIndex[i] = enc
+		res.byName[ct.Name()] = enc
+	}
+
+	return res
+}
+
+func (e valueEncoder) encodeMap(row map[string]interface{}) error {
+	if e.byName == nil {
+		mapBytesToString(row)
+		return nil
+	}
+
+	for k, v := range row {
+		v, err := e.encode(e.byName[k], k, v)
+		if err != nil {
+			return err
+		}
+		row[k] = v
+	}
+
+	return nil
+}
+
+func (e valueEncoder) encodeSlice(values []interface{}) error {
+	if e.byIndex == nil {
+		sliceBytesToString(values)
+		return nil
+	}
+
+	for i, v := range values {
+		var (
+			enc    Encoder
+			column = strconv.Itoa(i)
+		)
+		if i < len(e.byIndex) {
+			enc, column = e.byIndex[i], e.columns[i]
+		}
+
+		v, err := e.encode(enc, column, v)
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+
+	return nil
+}
+
+func (valueEncoder) encode(enc Encoder, column string, v interface{}) (interface{}, error) {
+	if enc == nil || v == nil {
+		if b, ok := v.([]byte); ok {
+			return string(b), nil
+		}
+		return v, nil
+	}
+
+	res, err := enc.Encode(v)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode column %s: %w", column, err)
+	}
+
+	return res, nil
+}
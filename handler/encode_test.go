@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/at-silva/ddapi/db"
+	"github.com/at-silva/ddapi/db/dbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Encoders", func() {
+
+	It("should encode decimals exactly", func() {
+		v, err := Decimal(false)([]byte("12345678901234567890.123456789"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).Should(Equal("12345678901234567890.123456789"))
+
+		v, err = Decimal(true)("0.10")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).Should(Equal(json.Number("0.10")))
+
+		_, err = Decimal(true)("NaN")
+		Expect(err).Should(MatchError(`could not encode decimal "NaN"`))
+	})
+
+	It("should encode binary as base64", func() {
+		v, err := Binary([]byte{0, 255, 1})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).Should(Equal("AP8B"))
+	})
+
+	It("should encode times in RFC 3339", func() {
+		for in, out := range map[interface{}]string{
+			time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC): "2024-01-02T03:04:05.000000006Z",
+			"2024-01-02 03:04:05":                       "2024-01-02T03:04:05",
+			"2024-01-02T03:04:05.25":                    "2024-01-02T03:04:05.25",
+			"2024-01-02 03:04:05.5+02:00":               "2024-01-02T03:04:05.5+02:00",
+			"2024-01-02 03:04:05Z":                      "2024-01-02T03:04:05Z",
+			"2024-01-02":                                "2024-01-02",
+			"03:04:05":                                  "03:04:05",
+		} {
+			v, err := Time(in)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v).Should(Equal(out), "%v", in)
+		}
+
+		v, err := Time([]byte("2024-01-02 03:04:05"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).Should(Equal("2024-01-02T03:04:05"))
+	})
+
+	It("should read times without a time zone in the given location", func() {
+		loc := time.FixedZone("", -3*60*60)
+		for in, out := range map[interface{}]string{
+			"2024-01-02 03:04:05":                       "2024-01-02T03:04:05-03:00",
+			"2024-01-02":                                "2024-01-02T00:00:00-03:00",
+			"2024-01-02 03:04:05.5+02:00":               "2024-01-02T03:04:05.5+02:00",
+			time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC): "2024-01-02T03:04:05Z",
+		} {
+			v, err := TimeIn(loc)(in)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v).Should(Equal(out), "%v", in)
+		}
+	})
+
+	It("should encode dates as full-dates", func() {
+		for in, out := range map[interface{}]string{
+			time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC):                     "2024-01-02",
+			time.Date(2024, 1, 2, 0, 0, 0, 0, time.FixedZone("", -5*60*60)): "2024-01-02",
+			"2024-01-02":           "2024-01-02",
+			"2024-01-02T00:00:00Z": "2024-01-02",
+			"2024-01-02 00:00:00":  "2024-01-02",
+			"not a date":           "not a date",
+		} {
+			v, err := Date(in)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v).Should(Equal(out), "%v", in)
+		}
+
+		v, err := Date([]byte("2024-01-02"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).Should(Equal("2024-01-02"))
+
+		v, err = Date(nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).Should(BeNil())
+	})
+
+	It("should embed JSON", func() {
+		v, err := JSON([]byte(`{"a":[1,2]}`))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).Should(Equal(json.RawMessage(`{"a":[1,2]}`)))
+
+		_, err = JSON("{")
+		Expect(err).Should(MatchError(`could not encode json "{"`))
+	})
+
+	It("should encode integers beyond 2^53 as strings", func() {
+		for in, out := range map[interface{}]interface{}{
+			int64(9007199254740991):      int64(9007199254740991),
+			int64(9007199254740993):      "9007199254740993",
+			int64(-9007199254740993):     "-9007199254740993",
+			uint64(18446744073709551615): "18446744073709551615",
+			"9223372036854775807":        "9223372036854775807",
+			"n/a":                        "n/a",
+		} {
+			v, err := Integer(in)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(v).Should(Equal(out), "%v", in)
+		}
+
+		v, err := Integer([]byte("42"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(v).Should(Equal(int64(42)))
+	})
+
+})
+
+var _ = Describe("encoded queryHandler", func() {
+
+	var (
+		fakeDB   *dbfakes.FakeDB
+		fakeRows *dbfakes.FakeRows
+
+		recorder *httptest.ResponseRecorder
+		serve    func(accept string, opts ...Option)
+	)
+
+	BeforeEach(func() {
+		fakeDB = new(dbfakes.FakeDB)
+		fakeRows = new(dbfakes.FakeRows)
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		var cts []db.ColumnType
+		for _, c := range [][]string{{"id", "BIGINT"}, {"price", "DECIMAL"}, {"picture", "BLOB"}, {"attrs", "json"}, {"name", "VARCHAR"}} {
+			ct := new(dbfakes.FakeColumnType)
+			ct.NameReturns(c[0])
+			ct.DatabaseTypeNameReturns(c[1])
+			cts = append(cts, ct)
+		}
+		fakeRows.ColumnTypesReturns(cts, nil)
+
+		values := []interface{}{[]byte("9007199254740993"), []byte("10.50"), []byte{0, 255, 1}, []byte(`{"color":"red"}`), []byte("Product 1")}
+		fakeRows.NextReturnsOnCall(0, true)
+		fakeRows.SliceScanStub = func() ([]interface{}, error) {
+			return append([]interface{}{}, values...), nil
+		}
+		fakeRows.MapScanStub = func(m map[string]interface{}) error {
+			for i, c := range []string{"id", "price", "picture", "attrs", "name"} {
+				m[c] = values[i]
+			}
+			return nil
+		}
+
+		recorder = httptest.NewRecorder()
+
+		serve = func(accept string, opts ...Option) {
+			serveDecoded(queryHandler{fakeDB, newOptions(opts)}, recorder, request{SQL: "select id, price, picture, attrs, name from product"}, map[string]interface{}{}, accept)
+		}
+	})
+
+	It("should encode values according to their column type", func() {
+		serve("application/json", WithEncoders(DefaultEncoders()))
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"data": [{
+				"id": "9007199254740993",
+				"price": "10.50",
+				"picture": "AP8B",
+				"attrs": {"color": "red"},
+				"name": "Product 1"
+			}],
+			"error": null
+		}`))
+	})
+
+	It("should encode columnar and CSV values", func() {
+		serve("application/vnd.ddapi.columnar+json", WithEncoders(DefaultEncoders()), WithStreaming(0, 0))
+		Expect(recorder.Body).Should(ContainSubstring(`"rows":[["9007199254740993","10.50","AP8B",{"color":"red"},"Product 1"]]`))
+
+		recorder = httptest.NewRecorder()
+		fakeRows.NextReturnsOnCall(2, true)
+		serve("text/csv", WithEncoders(DefaultEncoders()))
+		Expect(recorder.Body.String()).Should(Equal("id,price,picture,attrs,name\r\n" +
+			"9007199254740993,10.50,AP8B,\"{\"\"color\"\":\"\"red\"\"}\",Product 1\r\n"))
+	})
+
+	It("should use the given encoders", func() {
+		serve("application/json", WithEncoders(Encoders{"DECIMAL": Decimal(true)}))
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).Should(ContainSubstring(`"price":10.50`))
+		Expect(recorder.Body.String()).Should(ContainSubstring(`"attrs":"{\"color\":\"red\"}"`))
+	})
+
+	It("should return InternalServerError when a value can't be encoded", func() {
+		serve("application/json", WithEncoders(Encoders{"VARCHAR": Encode(JSON)}))
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"error": "could not encode column name: could not encode json \"Product 1\""
+		}`))
+	})
+
+})
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,29 +13,43 @@ import (
 
 	"github.com/at-silva/ddapi/check"
 	"github.com/at-silva/ddapi/db"
+	"github.com/at-silva/ddapi/statement"
 )
 
 type (
 	execHandler struct {
 		db db.DB
+		o  options
+	}
+
+	namedExecer interface {
+		NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
 	}
 
 	execResponse struct {
-		RowsAffected   int64   `json:"rowsAffected"`
-		LastInsertedID int64   `json:"lastInsertedId"`
-		Error          *string `json:"error"`
+		RowsAffected   int64         `json:"rowsAffected"`
+		LastInsertedID *int64        `json:"lastInsertedId,omitempty"`
+		Rows           interface{}   `json:"rows,omitempty"`
+		Results        []batchResult `json:"results,omitempty"`
+		Error          *string       `json:"error"`
 	}
 )
 
 // NewExec returns a new DDApi exec handler
-func NewExec(db db.DB, sc check.SignatureChecker, s session.Reader, pc check.ParamsChecker) http.Handler {
+func NewExec(db db.DB, sc check.SignatureChecker, s session.Reader, pc check.ParamsChecker, opts ...Option) http.Handler {
 	h := DecodeRequest(
 		CheckSignatures(sc,
-			ReadSession(s,
-				CheckParams(pc,
-					execHandler{
-						db,
-					}))))
+			CheckKind(statement.Exec,
+				ReadSession(s,
+					CheckParams(pc,
+						Idempotent(s,
+							execHandler{
+								db,
+								newOptions(opts),
+							},
+							opts...)))),
+			opts...),
+		opts...)
 
 	return h
 }
@@ -53,30 +70,51 @@ func (h execHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params, ok := r.Context().Value(DecodedParams).(map[string]interface{})
-	if !ok {
+	bulk, isBulk := r.Context().Value(DecodedParams).([]map[string]interface{})
+	if !ok && !isBulk {
 		http.Error(w, execError(fmt.Errorf("could not query the database: invalid params")), http.StatusInternalServerError)
 		return
 	}
 
-	res, err := h.db.NamedExecContext(r.Context(), q.SQL, params)
+	_, expects, err := expectedRows(q)
 	if err != nil {
-		http.Error(w, execError(fmt.Errorf("could not query the database: %w", err)), http.StatusInternalServerError)
+		http.Error(w, execError(err), http.StatusInternalServerError)
 		return
 	}
 
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
-		http.Error(w, execError(fmt.Errorf("could not read the number of rows affected: %w", err)), http.StatusInternalServerError)
+	// bulk statements and statements expecting a number of rows affected always run in a transaction,
+	// so they can be rolled back
+	o := h.o
+	if (expects || isBulk) && o.txOptions == nil {
+		o.txOptions = &sql.TxOptions{}
+	}
+
+	var res execResponse
+	err = transact(r.Context(), h.db, o, func(e executor) error {
+		var err error
+		if isBulk {
+			res, err = execBulk(r.Context(), e, h.db.Dialect(), q, bulk, h.o.encoders)
+			return err
+		}
+
+		res, err = execStatement(r.Context(), e, h.db.Dialect(), q, params, h.o.encoders)
+		if err != nil {
+			return err
+		}
+
+		return checkRowsAffected(q, res)
+	})
+	if errors.Is(err, errRowsAffected) {
+		http.Error(w, execError(err), http.StatusConflict)
 		return
 	}
 
-	lastInsertedID, err := res.LastInsertId()
 	if err != nil {
-		http.Error(w, execError(fmt.Errorf("could not read the last inserted id: %w", err)), http.StatusInternalServerError)
+		http.Error(w, execError(err), http.StatusInternalServerError)
 		return
 	}
 
-	resp, err := json.Marshal(execResponse{rowsAffected, lastInsertedID, nil})
+	resp, err := json.Marshal(res)
 	if err != nil {
 		log.Printf("marshal failed: %v", err)
 		return
@@ -87,3 +125,68 @@ func (h execHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		log.Printf("write failed: %v", err)
 	}
 }
+
+// execStatement executes a statement, statements with a RETURNING clause are run as queries and their rows
+// returned along with the number of rows affected. The last inserted id is left out when the driver doesn't
+// support it, instead of being reported as 0
+func execStatement(ctx context.Context, e executor, d db.Dialect, q request, params map[string]interface{}, enc Encoders) (execResponse, error) {
+	if returning(q, d) {
+		rows, _, err := queryStatement(ctx, e, q.SQL, params, enc)
+		if err != nil {
+			return execResponse{}, err
+		}
+
+		if rows == nil {
+			rows = []interface{}{}
+		}
+
+		return execResponse{RowsAffected: int64(len(rows)), Rows: rows}, nil
+	}
+
+	res, err := e.NamedExecContext(ctx, q.SQL, params)
+	if err != nil {
+		return execResponse{}, fmt.Errorf("could not query the database: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return execResponse{}, fmt.Errorf("could not read the number of rows affected: %w", err)
+	}
+
+	er := execResponse{RowsAffected: rowsAffected}
+	if !d.LastInsertID() {
+		return er, nil
+	}
+
+	lastInsertedID, err := res.LastInsertId()
+	if err != nil && (d != db.Unknown || !unsupported(err)) {
+		return execResponse{}, fmt.Errorf("could not read the last inserted id: %w", err)
+	}
+
+	if err == nil {
+		er.LastInsertedID = &lastInsertedID
+	}
+
+	return er, nil
+}
+
+// execBulk executes a statement once per params element, reporting the result of every execution along with the
+// total number of rows affected
+func execBulk(ctx context.Context, e executor, d db.Dialect, q request, bulk []map[string]interface{}, enc Encoders) (execResponse, error) {
+	res := execResponse{Results: make([]batchResult, 0, len(bulk))}
+	for i, p := range bulk {
+		r, err := execStatement(ctx, e, d, q, p, enc)
+		if err == nil {
+			err = checkRowsAffected(q, r)
+		}
+		if err != nil {
+			return execResponse{}, fmt.Errorf("row %d: %w", i, err)
+		}
+
+		res.RowsAffected += r.RowsAffected
+		res.LastInsertedID = r.LastInsertedID
+		res.Results = append(res.Results, batchResult{r.RowsAffected, r.LastInsertedID, r.Rows})
+	}
+
+	return res, nil
+}
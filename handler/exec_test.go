@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 
@@ -26,7 +27,7 @@ var _ = Describe("execHandler", func() {
 		fakeResult = new(dbfakes.FakeResult)
 
 		recorder = httptest.NewRecorder()
-		ehandler = execHandler{fakeDB}
+		ehandler = execHandler{db: fakeDB}
 	})
 
 	It("should return Ok when a database call succeeds", func() {
@@ -72,7 +73,6 @@ var _ = Describe("execHandler", func() {
 		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
 		Expect(recorder.Body).Should(MatchJSON(`{
 			"rowsAffected": 0,
-			"lastInsertedId": 0,
 			"error":"could not query the database: invalid request" 
 		}`))
 		Expect(fakeDB.NamedExecContextCallCount()).Should(BeZero())
@@ -93,7 +93,6 @@ var _ = Describe("execHandler", func() {
 		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
 		Expect(recorder.Body).Should(MatchJSON(`{
 			"rowsAffected": 0,
-			"lastInsertedId": 0,
 			"error":"could not query the database: invalid params" 
 		}`))
 		Expect(fakeDB.NamedExecContextCallCount()).Should(BeZero())
@@ -122,7 +121,6 @@ var _ = Describe("execHandler", func() {
 		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
 		Expect(recorder.Body).Should(MatchJSON(`{
 			"rowsAffected": 0,
-			"lastInsertedId": 0,
 			"error":"could not query the database: sql: no rows in result set" 
 		}`))
 		_, sql, p := fakeDB.NamedExecContextArgsForCall(0)
@@ -153,7 +151,6 @@ var _ = Describe("execHandler", func() {
 		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
 		Expect(recorder.Body).Should(MatchJSON(`{
 			"rowsAffected": 0,
-			"lastInsertedId": 0,
 			"error":"could not read the number of rows affected: sql: connection is already closed" 
 		}`))
 		_, sql, p := fakeDB.NamedExecContextArgsForCall(0)
@@ -184,7 +181,6 @@ var _ = Describe("execHandler", func() {
 		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
 		Expect(recorder.Body).Should(MatchJSON(`{
 			"rowsAffected": 0,
-			"lastInsertedId": 0,
 			"error":"could not read the last inserted id: sql: connection is already closed" 
 		}`))
 		_, sql, p := fakeDB.NamedExecContextArgsForCall(0)
@@ -192,4 +188,91 @@ var _ = Describe("execHandler", func() {
 		Expect(p).Should(Equal(params))
 	})
 
+	It("should roll back the transaction when configured with an isolation level and the database call fails", func() {
+		fakeTx := new(dbfakes.FakeTx)
+		fakeDB.BeginTxxReturns(fakeTx, nil)
+		fakeTx.NamedExecContextReturns(nil, sql.ErrTxDone)
+		ehandler = execHandler{fakeDB, newOptions([]Option{WithIsolationLevel(sql.LevelSerializable)})}
+
+		req := request{SQL: "insert into product(name) values(:name)"}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		ctx = context.WithValue(ctx, DecodedParams, map[string]interface{}{"name": "Product1"})
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"rowsAffected": 0,
+			"error":"could not query the database: sql: transaction has already been committed or rolled back"
+		}`))
+		_, txOpts := fakeDB.BeginTxxArgsForCall(0)
+		Expect(txOpts.Isolation).Should(Equal(sql.LevelSerializable))
+		Expect(fakeDB.NamedExecContextCallCount()).Should(BeZero())
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+		Expect(fakeTx.CommitCallCount()).Should(BeZero())
+	})
+
+	It("should execute bulk params once per element in a single transaction", func() {
+		fakeTx := new(dbfakes.FakeTx)
+		fakeDB.BeginTxxReturns(fakeTx, nil)
+		fakeTx.NamedExecContextReturns(fakeResult, nil)
+		fakeResult.LastInsertIdReturnsOnCall(0, 1, nil)
+		fakeResult.LastInsertIdReturnsOnCall(1, 2, nil)
+		fakeResult.RowsAffectedReturns(1, nil)
+
+		req := request{SQL: "insert into product(name) values(:name)"}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		bulk := []map[string]interface{}{{"name": "Product1"}, {"name": "Product2"}}
+		ctx = context.WithValue(ctx, DecodedParams, bulk)
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK), recorder.Body.String())
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"rowsAffected": 2,
+			"lastInsertedId": 2,
+			"results": [
+				{"rowsAffected": 1, "lastInsertedId": 1},
+				{"rowsAffected": 1, "lastInsertedId": 2}
+			],
+			"error": null
+		}`))
+		Expect(fakeDB.BeginTxxCallCount()).Should(Equal(1))
+		Expect(fakeTx.NamedExecContextCallCount()).Should(Equal(2))
+		_, _, p := fakeTx.NamedExecContextArgsForCall(1)
+		Expect(p).Should(Equal(bulk[1]))
+		Expect(fakeTx.CommitCallCount()).Should(Equal(1))
+	})
+
+	It("should roll back bulk params when an element fails", func() {
+		fakeTx := new(dbfakes.FakeTx)
+		fakeDB.BeginTxxReturns(fakeTx, nil)
+		fakeTx.NamedExecContextReturns(fakeResult, nil)
+		fakeTx.NamedExecContextReturnsOnCall(1, nil, errors.New("duplicate key"))
+		fakeResult.RowsAffectedReturns(1, nil)
+
+		req := request{SQL: "insert into product(name) values(:name)"}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		ctx = context.WithValue(ctx, DecodedParams, []map[string]interface{}{{"name": "Product1"}, {"name": "Product1"}})
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"rowsAffected": 0,
+			"error":"row 1: could not query the database: duplicate key"
+		}`))
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+		Expect(fakeTx.CommitCallCount()).Should(BeZero())
+	})
+
 })
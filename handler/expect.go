@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errRowsAffected the number of rows affected by a statement differs from the one it expects
+var errRowsAffected = errors.New("unexpected number of rows affected")
+
+// expectedRows returns the number of rows a statement is expected to affect, signed in its meta as
+// {"expectRows": 1}, or false when the statement doesn't expect any
+func expectedRows(q request) (int64, bool, error) {
+	v, ok := q.Meta["expectRows"]
+	if !ok {
+		return 0, false, nil
+	}
+
+	n, ok := v.(float64)
+	if !ok || n < 0 || n != float64(int64(n)) {
+		return 0, false, fmt.Errorf("could not check rows affected: invalid expectRows meta")
+	}
+
+	return int64(n), true, nil
+}
+
+// checkRowsAffected fails with errRowsAffected when the statement affected another number of rows than expected
+func checkRowsAffected(q request, res execResponse) error {
+	n, ok, err := expectedRows(q)
+	if err != nil || !ok {
+		return err
+	}
+
+	if res.RowsAffected != n {
+		return fmt.Errorf("could not execute statement: %w: expected %d, got %d", errRowsAffected, n, res.RowsAffected)
+	}
+
+	return nil
+}
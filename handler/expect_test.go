@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/at-silva/ddapi/check/checkfakes"
+	"github.com/at-silva/ddapi/db/dbfakes"
+	"github.com/at-silva/ddapi/session/sessionfakes"
+	"github.com/at-silva/ddapi/statement"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("expected rows", func() {
+
+	const sql = "update product set name = :name, version = version + 1 where id = :id and version = :version"
+
+	var (
+		fakeDB     *dbfakes.FakeDB
+		fakeTx     *dbfakes.FakeTx
+		fakeResult *dbfakes.FakeResult
+		recorder   *httptest.ResponseRecorder
+		serve      func(meta map[string]interface{})
+	)
+
+	BeforeEach(func() {
+		fakeDB = new(dbfakes.FakeDB)
+		fakeTx = new(dbfakes.FakeTx)
+		fakeResult = new(dbfakes.FakeResult)
+		fakeDB.BeginTxxReturns(fakeTx, nil)
+		fakeTx.NamedExecContextReturns(fakeResult, nil)
+		recorder = httptest.NewRecorder()
+
+		serve = func(meta map[string]interface{}) {
+			params := map[string]interface{}{"id": 1, "name": "Product 1", "version": 3}
+			serveDecoded(execHandler{db: fakeDB}, recorder, request{SQL: sql, Meta: meta}, params, "")
+		}
+	})
+
+	It("should commit when the statement affects the expected number of rows", func() {
+		fakeResult.RowsAffectedReturns(1, nil)
+
+		serve(map[string]interface{}{"expectRows": float64(1)})
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(fakeDB.BeginTxxCallCount()).Should(Equal(1))
+		Expect(fakeTx.CommitCallCount()).Should(Equal(1))
+		Expect(fakeDB.NamedExecContextCallCount()).Should(BeZero())
+	})
+
+	It("should return Conflict and roll back when the statement affects another number of rows", func() {
+		fakeResult.RowsAffectedReturns(0, nil)
+
+		serve(map[string]interface{}{"expectRows": float64(1)})
+
+		Expect(recorder.Code).Should(Equal(http.StatusConflict))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"rowsAffected": 0,
+			"error": "could not execute statement: unexpected number of rows affected: expected 1, got 0"
+		}`))
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+		Expect(fakeTx.CommitCallCount()).Should(BeZero())
+	})
+
+	It("should fail if the expectRows meta is invalid", func() {
+		for _, v := range []interface{}{"1", float64(-1), 1.5} {
+			recorder = httptest.NewRecorder()
+
+			serve(map[string]interface{}{"expectRows": v})
+
+			Expect(recorder.Code).Should(Equal(http.StatusInternalServerError), "%v", v)
+			Expect(recorder.Body).Should(MatchJSON(`{
+				"rowsAffected": 0,
+				"error": "could not check rows affected: invalid expectRows meta"
+			}`))
+		}
+		Expect(fakeDB.BeginTxxCallCount()).Should(BeZero())
+	})
+
+	It("should not begin a transaction for statements without expectations", func() {
+		fakeDB.NamedExecContextReturns(fakeResult, nil)
+
+		serve(nil)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(fakeDB.BeginTxxCallCount()).Should(BeZero())
+	})
+
+	It("should roll back the whole batch on conflict", func() {
+		fakeResult.RowsAffectedReturnsOnCall(0, 1, nil)
+		fakeResult.RowsAffectedReturnsOnCall(1, 0, nil)
+
+		e, err := statement.Encode(statement.Statement{Kind: statement.Exec, SQL: sql, ParamsSchema: []byte(`{}`), Meta: map[string]interface{}{"expectRows": 1}})
+		Expect(err).ShouldNot(HaveOccurred())
+		item := `{"statement":"` + e + `","statementSignature":"c2lnbmF0dXJl","params":{"id":1,"version":3}}`
+
+		h := NewBatch(fakeDB, new(checkfakes.FakeSignatureChecker), new(sessionfakes.FakeReader), new(checkfakes.FakeParamsChecker))
+		request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/batch", bytes.NewBufferString(`{"statements":[`+item+`,`+item+`]}`))
+		Expect(err).ShouldNot(HaveOccurred())
+		request.Header.Set("Authorization", "Bearer token")
+
+		h.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusConflict))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"results": null,
+			"error": "statement 1: could not execute statement: unexpected number of rows affected: expected 1, got 0"
+		}`))
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+	})
+
+})
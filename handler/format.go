@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/at-silva/ddapi/db"
+)
+
+type (
+	// rowFormat serializes the rows of a query result, in the order of the result set columns
+	rowFormat interface {
+		contentType() string
+		begin(cts []db.ColumnType) ([]byte, error)
+		row(n int, columns []string, row map[string]interface{}) ([]byte, error)
+		end(err error) []byte
+	}
+
+	// sliceFormat is implemented by the formats serializing rows as slices, so duplicate column names are kept
+	sliceFormat interface {
+		rowFormat
+		values(n int, values []interface{}) ([]byte, error)
+	}
+
+	// jsonFormat writes a queryResponse, the error is written once every row is
+	jsonFormat struct{}
+
+	// ndjsonFormat writes one row per line, followed by an {"error": "..."} line when the result is incomplete
+	ndjsonFormat struct{}
+
+	// csvFormat writes a header row followed by one record per row, and a final "error: ..." record when the
+	// result is incomplete. Records are CRLF terminated so they open as is in spreadsheets
+	csvFormat struct {
+		mediaType string
+		comma     rune
+	}
+
+	// columnarFormat writes a columnarResponse, the columns along with their database type followed by the rows
+	// as arrays, in the order of the result set
+	columnarFormat struct{}
+
+	column struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+)
+
+var formats = map[string]rowFormat{
+	"json":   jsonFormat{},
+	"ndjson": ndjsonFormat{},
+	"csv":    csvFormat{"text/csv", ','},
+	"tsv":    csvFormat{"text/tab-separated-values", '\t'},
+
+	"columnar": columnarFormat{},
+}
+
+// queryFormat returns the format signed in the statement meta, e.g. {"format": "csv"}, or else the supported
+// format the client prefers by q-value, the first one listed winning ties. Formats with a q-value of 0 are refused,
+// wildcards stand for JSON, which is the default
+func queryFormat(r *http.Request, q request) (rowFormat, error) {
+	if name, ok := q.Meta["format"].(string); ok {
+		f, ok := formats[name]
+		if !ok {
+			return nil, fmt.Errorf("could not negotiate format: unknown format %q", name)
+		}
+
+		return f, nil
+	}
+
+	var (
+		best  rowFormat = jsonFormat{}
+		bestQ float64
+	)
+
+	for _, a := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, ps, err := mime.ParseMediaType(strings.TrimSpace(a))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := ps["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		f := acceptedFormat(mt)
+		if f != nil && q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+
+	return best, nil
+}
+
+// acceptedFormat returns the format of an accepted media type, if supported
+func acceptedFormat(mt string) rowFormat {
+	if mt == "*/*" || mt == "application/*" {
+		return jsonFormat{}
+	}
+
+	for _, f := range formats {
+		if f.contentType() == mt {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func (jsonFormat) contentType() string {
+	return "application/json"
+}
+
+func (jsonFormat) begin([]db.ColumnType) ([]byte, error) {
+	return []byte(`{"data":[`), nil
+}
+
+func (jsonFormat) row(n int, _ []string, row map[string]interface{}) ([]byte, error) {
+	return arrayElement(n, row)
+}
+
+func (jsonFormat) end(err error) []byte {
+	if err == nil {
+		return []byte(`],"error":null}`)
+	}
+
+	e, _ := json.Marshal(err.Error())
+	return []byte(`],"error":` + string(e) + `}`)
+}
+
+func (ndjsonFormat) contentType() string {
+	return "application/x-ndjson"
+}
+
+func (ndjsonFormat) begin([]db.ColumnType) ([]byte, error) {
+	return nil, nil
+}
+
+func (ndjsonFormat) row(_ int, _ []string, row map[string]interface{}) ([]byte, error) {
+	b, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+func (ndjsonFormat) end(err error) []byte {
+	if err == nil {
+		return nil
+	}
+
+	return []byte(errEncode(err) + "\n")
+}
+
+func (f csvFormat) contentType() string {
+	return f.mediaType
+}
+
+func (f csvFormat) begin(cts []db.ColumnType) ([]byte, error) {
+	return f.record(columnNames(cts))
+}
+
+func (f csvFormat) row(_ int, columns []string, row map[string]interface{}) ([]byte, error) {
+	rec := make([]string, len(columns))
+	for i, c := range columns {
+		rec[i] = csvValue(row[c])
+	}
+
+	return f.record(rec)
+}
+
+func (f csvFormat) values(_ int, values []interface{}) ([]byte, error) {
+	rec := make([]string, len(values))
+	for i, v := range values {
+		rec[i] = csvValue(v)
+	}
+
+	return f.record(rec)
+}
+
+func (f csvFormat) end(err error) []byte {
+	if err == nil {
+		return nil
+	}
+
+	b, _ := f.record([]string{"error: " + err.Error()})
+	return b
+}
+
+func (f csvFormat) record(rec []string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = f.comma
+	w.UseCRLF = true
+
+	err := w.Write(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.RawMessage:
+		return string(v)
+	case json.Number:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (columnarFormat) contentType() string {
+	return "application/vnd.ddapi.columnar+json"
+}
+
+func (columnarFormat) begin(cts []db.ColumnType) ([]byte, error) {
+	columns := make([]column, len(cts))
+	for i, ct := range cts {
+		columns[i] = column{ct.Name(), ct.DatabaseTypeName()}
+	}
+
+	b, err := json.Marshal(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(`{"columns":` + string(b) + `,"rows":[`), nil
+}
+
+func (f columnarFormat) row(n int, columns []string, row map[string]interface{}) ([]byte, error) {
+	values := make([]interface{}, len(columns))
+	for i, c := range columns {
+		values[i] = row[c]
+	}
+
+	return f.values(n, values)
+}
+
+func (columnarFormat) values(n int, values []interface{}) ([]byte, error) {
+	return arrayElement(n, values)
+}
+
+func (columnarFormat) end(err error) []byte {
+	return jsonFormat{}.end(err)
+}
+
+// arrayElement serializes the nth element of a JSON array
+func arrayElement(n int, v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || n == 0 {
+		return b, err
+	}
+
+	return append([]byte(","), b...), nil
+}
+
+func columnNames(cts []db.ColumnType) []string {
+	res := make([]string, len(cts))
+	for i, ct := range cts {
+		res[i] = ct.Name()
+	}
+
+	return res
+}
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/at-silva/ddapi/db"
+	"github.com/at-silva/ddapi/db/dbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("queryFormat", func() {
+
+	var accept func(a string) *http.Request
+
+	BeforeEach(func() {
+		accept = func(a string) *http.Request {
+			r, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/query", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			r.Header.Set("Accept", a)
+			return r
+		}
+	})
+
+	It("should negotiate the format through the Accept header", func() {
+		for a, ct := range map[string]string{
+			"":                                     "application/json",
+			"*/*":                                  "application/json",
+			"text/html, application/x-ndjson":      "application/x-ndjson",
+			"text/csv; charset=utf-8":              "text/csv",
+			"text/tab-separated-values;q=0.9, */*": "application/json",
+			"text/tab-separated-values;q=0.9, */*;q=0.1": "text/tab-separated-values",
+			"text/csv;q=0, application/json":             "application/json",
+			"text/csv;q=0":                               "application/json",
+			"application/json;q=0.5, text/csv;q=0.8":     "text/csv",
+			"text/csv, application/x-ndjson":             "text/csv",
+			"text/csv;q=0.5, application/x-ndjson;q=0.5": "text/csv",
+			"application/*;q=0.9, text/csv;q=0.2":        "application/json",
+			"text/csv;q=abc, application/x-ndjson;q=0.1": "application/x-ndjson",
+			"application/xml":                            "application/json",
+			"application/vnd.ddapi.columnar+json":        "application/vnd.ddapi.columnar+json",
+		} {
+			f, err := queryFormat(accept(a), request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(f.contentType()).Should(Equal(ct), a)
+		}
+	})
+
+	It("should prefer the format signed in the statement meta", func() {
+		f, err := queryFormat(accept("application/json"), request{Meta: map[string]interface{}{"format": "tsv"}})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(f.contentType()).Should(Equal("text/tab-separated-values"))
+	})
+
+	It("should fail if the signed format is unknown", func() {
+		_, err := queryFormat(accept(""), request{Meta: map[string]interface{}{"format": "xlsx"}})
+		Expect(err).Should(MatchError(`could not negotiate format: unknown format "xlsx"`))
+	})
+
+})
+
+var _ = Describe("formatted queryHandler", func() {
+
+	var (
+		fakeDB   *dbfakes.FakeDB
+		fakeRows *dbfakes.FakeRows
+
+		recorder *httptest.ResponseRecorder
+		serve    func(accept string, opts ...Option)
+	)
+
+	BeforeEach(func() {
+		fakeDB = new(dbfakes.FakeDB)
+		fakeRows = new(dbfakes.FakeRows)
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		var cts []db.ColumnType
+		for _, n := range []string{"name", "id", "created_at"} {
+			ct := new(dbfakes.FakeColumnType)
+			ct.NameReturns(n)
+			cts = append(cts, ct)
+		}
+		fakeRows.ColumnTypesReturns(cts, nil)
+
+		fakeRows.NextReturnsOnCall(0, true)
+		fakeRows.NextReturnsOnCall(1, true)
+		product := func(n int) []interface{} {
+			values := []interface{}{[]byte("Product, \"" + string(rune('A'-1+n)) + "\""), n, nil}
+			if n == 1 {
+				values[2] = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			}
+			return values
+		}
+		fakeRows.SliceScanStub = func() ([]interface{}, error) {
+			return product(fakeRows.SliceScanCallCount()), nil
+		}
+		fakeRows.MapScanStub = func(m map[string]interface{}) error {
+			for i, v := range product(fakeRows.MapScanCallCount()) {
+				m[[]string{"name", "id", "created_at"}[i]] = v
+			}
+			return nil
+		}
+
+		recorder = httptest.NewRecorder()
+
+		serve = func(accept string, opts ...Option) {
+			serveDecoded(queryHandler{fakeDB, newOptions(opts)}, recorder, request{SQL: "select name, id, created_at from product"}, map[string]interface{}{}, accept)
+		}
+	})
+
+	It("should write CSV in the order of the result set columns", func() {
+		serve("text/csv")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).Should(Equal("text/csv"))
+		Expect(recorder.Body.String()).Should(Equal("name,id,created_at\r\n" +
+			"\"Product, \"\"A\"\"\",1,2024-01-02T03:04:05Z\r\n" +
+			"\"Product, \"\"B\"\"\",2,\r\n"))
+		Expect(fakeRows.CloseCallCount()).Should(Equal(1))
+	})
+
+	It("should write TSV", func() {
+		serve("text/tab-separated-values")
+
+		Expect(recorder.Header().Get("Content-Type")).Should(Equal("text/tab-separated-values"))
+		Expect(recorder.Body.String()).Should(Equal("name\tid\tcreated_at\r\n" +
+			"\"Product, \"\"A\"\"\"\t1\t2024-01-02T03:04:05Z\r\n" +
+			"\"Product, \"\"B\"\"\"\t2\t\r\n"))
+	})
+
+	It("should write NDJSON", func() {
+		serve("application/x-ndjson")
+
+		Expect(recorder.Header().Get("Content-Type")).Should(Equal("application/x-ndjson"))
+		Expect(recorder.Body.String()).Should(Equal(`{"created_at":"2024-01-02T03:04:05Z","id":1,"name":"Product, \"A\""}` + "\n" +
+			`{"created_at":null,"id":2,"name":"Product, \"B\""}` + "\n"))
+	})
+
+	It("should not write a partial result when it is not streamed", func() {
+		fakeRows.ErrReturns(sql.ErrConnDone)
+
+		serve("text/csv")
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"error":"could not read rows: sql: connection is already closed"
+		}`))
+	})
+
+	It("should terminate streamed CSV with an error record", func() {
+		serve("text/csv", WithStreaming(1, 0))
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).Should(Equal("name,id,created_at\r\n" +
+			"\"Product, \"\"A\"\"\",1,2024-01-02T03:04:05Z\r\n" +
+			"error: could not stream rows: row limit of 1 exceeded\r\n"))
+	})
+
+	It("should return BadRequest when the signed format is unknown", func() {
+		req := request{SQL: "select * from product", Meta: map[string]interface{}{"format": "xlsx"}}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		ctx = context.WithValue(ctx, DecodedParams, map[string]interface{}{})
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		queryHandler{db: fakeDB}.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(fakeDB.NamedQueryContextCallCount()).Should(BeZero())
+	})
+
+	It("should write the columnar shape, keeping duplicate columns", func() {
+		var cts []db.ColumnType
+		for _, c := range [][]string{{"id", "INT4"}, {"name", "TEXT"}, {"id", "INT8"}} {
+			ct := new(dbfakes.FakeColumnType)
+			ct.NameReturns(c[0])
+			ct.DatabaseTypeNameReturns(c[1])
+			cts = append(cts, ct)
+		}
+		fakeRows.ColumnTypesReturns(cts, nil)
+		fakeRows.SliceScanStub = func() ([]interface{}, error) {
+			n := fakeRows.SliceScanCallCount()
+			return []interface{}{n, []byte("Product"), n * 10}, nil
+		}
+
+		serve("application/vnd.ddapi.columnar+json")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).Should(Equal("application/vnd.ddapi.columnar+json"))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"columns": [
+				{"name": "id", "type": "INT4"},
+				{"name": "name", "type": "TEXT"},
+				{"name": "id", "type": "INT8"}
+			],
+			"rows": [
+				[1, "Product", 10],
+				[2, "Product", 20]
+			],
+			"error": null
+		}`))
+		Expect(fakeRows.MapScanCallCount()).Should(BeZero())
+	})
+
+	It("should stream the columnar shape", func() {
+		fakeRows.SliceScanStub = func() ([]interface{}, error) {
+			return []interface{}{"Product", 1, nil}, nil
+		}
+
+		serve("application/vnd.ddapi.columnar+json", WithStreaming(1, 0))
+
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"columns": [
+				{"name": "name", "type": ""},
+				{"name": "id", "type": ""},
+				{"name": "created_at", "type": ""}
+			],
+			"rows": [["Product", 1, null]],
+			"error": "could not stream rows: row limit of 1 exceeded"
+		}`))
+	})
+
+})
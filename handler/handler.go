@@ -1,15 +1,29 @@
 /*Package handler contains a set of http handlers to address:
+batch: transactional execution of several DML statements
 decode: DDAPI requests decoding
-exec: DML execution
+encode: query result values encoding by column type
+exec: DML execution, in bulk when given an array of params
+expect: expected number of rows affected by DML statements
+format: JSON/NDJSON/CSV/TSV/columnar query result formats
+idempotency: Idempotency-Key replay of exec requests
+kind: query/exec statement kind enforcement
+page: keyset pagination with signed cursors
 params: query/statement parameters validation
 query: DQL execution
+result: one/maybeOne/scalar query result modes
+returning: RETURNING/OUTPUT clause detection
 session: JWT/session introspection
+shape: nesting of flat join rows
 signature: query/statement signature checking
+stream: streamed query responses
+tx: transaction options shared by the query, exec and batch handlers
 */
 package handler
 
 import (
 	"encoding/json"
+
+	"github.com/at-silva/ddapi/statement"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 net/http.Handler
@@ -21,6 +35,16 @@ type (
 		Params                string `json:"params"`
 		ParamsSchema          string `json:"paramsSchema"`
 		ParamsSchemaSignature string `json:"paramsSchemaSignature"`
+		Statement             string `json:"statement"`
+		StatementSignature    string `json:"statementSignature"`
+		EncryptedStatement    string `json:"encryptedStatement"`
+		StatementID           string `json:"statementId"`
+		Cursor                string `json:"cursor"`
+		PageSize              int    `json:"pageSize"`
+
+		Kind       statement.Kind         `json:"-"`
+		Meta       map[string]interface{} `json:"-"`
+		registered bool
 	}
 	alias request
 )
@@ -43,6 +67,12 @@ func (r *request) UnmarshalJSON(data []byte) error {
 	r.Params = string(aux.Params)
 	r.ParamsSchema = string(aux.ParamsSchema)
 	r.ParamsSchemaSignature = aux.ParamsSchemaSignature
+	r.Statement = aux.Statement
+	r.StatementSignature = aux.StatementSignature
+	r.EncryptedStatement = aux.EncryptedStatement
+	r.StatementID = aux.StatementID
+	r.Cursor = aux.Cursor
+	r.PageSize = aux.PageSize
 	return nil
 }
 
@@ -54,6 +84,14 @@ func mapBytesToString(s map[string]interface{}) {
 	}
 }
 
+func sliceBytesToString(s []interface{}) {
+	for i, v := range s {
+		if b, ok := v.([]byte); ok {
+			s[i] = string(b)
+		}
+	}
+}
+
 func errEncode(e error) string {
 	res, _ := json.Marshal(&struct {
 		Error string `json:"error"`
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"context"
+	"net/http"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
@@ -11,3 +13,17 @@ func TestHandler(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Handler Suite")
 }
+
+// serveDecoded serves a request as the handlers get it once decoded, along with its Accept header if any
+func serveDecoded(h http.Handler, w http.ResponseWriter, req request, params map[string]interface{}, accept string) {
+	ctx := context.WithValue(context.Background(), DecodedRequest, req)
+	ctx = context.WithValue(ctx, DecodedParams, params)
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+
+	h.ServeHTTP(w, r)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/at-silva/ddapi/idempotency"
+	"github.com/at-silva/ddapi/session"
+)
+
+const (
+	// IdempotencyKeyHeader the header carrying the idempotency key of exec requests
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader the header set on responses replayed from the idempotency store
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+
+	// idempotencyFinishTimeout bounds the completion or release of a key, which outlives the request
+	idempotencyFinishTimeout = 5 * time.Second
+)
+
+type responseCapture struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+// Idempotent stores the first response sent for every Idempotency-Key header and session subject, see
+// WithIdempotency, and replays it when the request is retried. Reusing a key with a different statement or
+// params is rejected with an UnprocessableEntity, retrying while the first request is in flight with a Conflict.
+// Requests failing with a server error release their key so they can be retried
+func Idempotent(s session.Reader, next http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if o.idempotency == nil || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, errEncode(fmt.Errorf("could not check idempotency key: longer than %d characters", maxIdempotencyKeyLength)), http.StatusBadRequest)
+			return
+		}
+
+		req, ok := r.Context().Value(DecodedRequest).(request)
+		if !ok {
+			http.Error(w, errEncode(fmt.Errorf("could not check idempotency key: invalid request")), http.StatusInternalServerError)
+			return
+		}
+
+		claims := map[string]interface{}{}
+		code, err := readSession(s, r, claims)
+		if err != nil {
+			http.Error(w, errEncode(err), code)
+			return
+		}
+
+		fp, err := fingerprint(req)
+		if err != nil {
+			http.Error(w, errEncode(err), http.StatusBadRequest)
+			return
+		}
+
+		// keys are never shared by users, requests without a subject are rejected rather than given a common one
+		sub, ok := claims[o.idempotencyClaim]
+		if !ok || sub == nil || sub == "" {
+			http.Error(w, errEncode(fmt.Errorf("could not check idempotency key: missing session claim %s", o.idempotencyClaim)), http.StatusBadRequest)
+			return
+		}
+
+		k := idempotency.Key{Subject: fmt.Sprint(sub), Key: key}
+
+		stored, ok, err := o.idempotency.Reserve(r.Context(), k, idempotency.Response{Fingerprint: fp})
+		if err != nil {
+			http.Error(w, errEncode(fmt.Errorf("could not check idempotency key: %w", err)), http.StatusInternalServerError)
+			return
+		}
+
+		if !ok {
+			replay(w, fp, stored)
+			return
+		}
+
+		c := &responseCapture{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(c, r)
+
+		// the request context is cancelled once the client goes away, which is when it retries, the key is
+		// finished regardless so the retry doesn't find it in progress forever
+		ctx, cancel := context.WithTimeout(context.Background(), idempotencyFinishTimeout)
+		defer cancel()
+
+		if c.code >= http.StatusInternalServerError {
+			err = o.idempotency.Release(ctx, k)
+			if err != nil {
+				log.Printf("release failed: %v", err)
+			}
+			return
+		}
+
+		err = o.idempotency.Complete(ctx, k, idempotency.Response{Fingerprint: fp, Code: c.code, Body: c.body.Bytes()})
+		if err != nil {
+			log.Printf("complete failed: %v", err)
+		}
+	})
+}
+
+// replay writes the stored response, unless it belongs to a different request or is still in flight
+func replay(w http.ResponseWriter, fp string, stored idempotency.Response) {
+	if stored.Fingerprint != fp {
+		http.Error(w, errEncode(fmt.Errorf("could not replay request: idempotency key reused with a different statement or params")), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if stored.Code == 0 {
+		http.Error(w, errEncode(fmt.Errorf("could not replay request: the first request is still in progress")), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.Code)
+
+	_, err := w.Write(stored.Body)
+	if err != nil {
+		log.Printf("write failed: %v", err)
+	}
+}
+
+// fingerprint hashes the statement along with the params sent by the client, the params are re-encoded so
+// their key order and spacing don't matter
+func fingerprint(req request) (string, error) {
+	var p interface{}
+	err := json.Unmarshal([]byte(req.Params), &p)
+	if err != nil {
+		return "", fmt.Errorf("could not unmarshal params: %w", err)
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal params: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write([]byte(req.SQL))
+	h.Write([]byte{'\n'})
+	h.Write(b)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *responseCapture) WriteHeader(code int) {
+	c.code = code
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/at-silva/ddapi/handler/handlerfakes"
+	"github.com/at-silva/ddapi/idempotency"
+	"github.com/at-silva/ddapi/idempotency/idempotencyfakes"
+	"github.com/at-silva/ddapi/session/sessionfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Idempotent", func() {
+
+	var (
+		fakeNext          *handlerfakes.FakeHandler
+		fakeSessionReader *sessionfakes.FakeReader
+		ehandler          http.Handler
+
+		ctx  context.Context
+		post func(key, token, params string) *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		fakeNext = new(handlerfakes.FakeHandler)
+		fakeSessionReader = new(sessionfakes.FakeReader)
+		fakeSessionReader.CopyStub = func(t string, p map[string]interface{}) error {
+			p["sub"] = t
+			return nil
+		}
+		fakeNext.ServeHTTPStub = func(w http.ResponseWriter, _ *http.Request) {
+			_, err := w.Write([]byte(`{"rowsAffected":1,"lastInsertedId":42,"error":null}`))
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+
+		ehandler = Idempotent(fakeSessionReader, fakeNext, WithIdempotency(idempotency.Memory(0), "sub"))
+		ctx = context.Background()
+
+		post = func(key, token, params string) *httptest.ResponseRecorder {
+			req := request{SQL: "insert into orders(product_id) values(:product_id)", Params: params}
+
+			request, err := http.NewRequestWithContext(context.WithValue(ctx, DecodedRequest, req), http.MethodPost, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			request.Header.Set("Authorization", "Bearer "+token)
+			if key != "" {
+				request.Header.Set(IdempotencyKeyHeader, key)
+			}
+
+			recorder := httptest.NewRecorder()
+			ehandler.ServeHTTP(recorder, request)
+			return recorder
+		}
+	})
+
+	It("should replay the first response when the request is retried", func() {
+		first := post("4f1c", "user-1", `{"product_id": 3}`)
+		Expect(first.Code).Should(Equal(http.StatusOK))
+		Expect(first.Header().Get(IdempotentReplayedHeader)).Should(BeEmpty())
+
+		retry := post("4f1c", "user-1", `{ "product_id":3 }`)
+		Expect(retry.Code).Should(Equal(http.StatusOK))
+		Expect(retry.Header().Get(IdempotentReplayedHeader)).Should(Equal("true"))
+		Expect(retry.Body).Should(MatchJSON(`{"rowsAffected":1,"lastInsertedId":42,"error":null}`))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
+	})
+
+	It("should scope the keys to the session subject", func() {
+		post("4f1c", "user-1", `{"product_id": 3}`)
+		post("4f1c", "user-2", `{"product_id": 3}`)
+
+		Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(2))
+	})
+
+	It("should return BadRequest when the session lacks the subject claim", func() {
+		fakeSessionReader.CopyStub = func(_ string, p map[string]interface{}) error {
+			p["role"] = "customer"
+			return nil
+		}
+
+		recorder := post("4f1c", "user-1", `{"product_id": 3}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{"error":"could not check idempotency key: missing session claim sub"}`))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+	})
+
+	It("should refuse an empty subject claim", func() {
+		Expect(func() { WithIdempotency(idempotency.Memory(0), "") }).Should(Panic())
+	})
+
+	It("should not store requests without a key", func() {
+		post("", "user-1", `{"product_id": 3}`)
+		post("", "user-1", `{"product_id": 3}`)
+
+		Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(2))
+	})
+
+	It("should return UnprocessableEntity when a key is reused with different params", func() {
+		post("4f1c", "user-1", `{"product_id": 3}`)
+		recorder := post("4f1c", "user-1", `{"product_id": 4}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusUnprocessableEntity))
+		Expect(recorder.Body).Should(MatchJSON(`{"error":"could not replay request: idempotency key reused with a different statement or params"}`))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
+	})
+
+	It("should return Conflict when the first request is still in progress", func() {
+		var retry *httptest.ResponseRecorder
+		fakeNext.ServeHTTPStub = func(w http.ResponseWriter, _ *http.Request) {
+			retry = post("4f1c", "user-1", `{"product_id": 3}`)
+		}
+
+		post("4f1c", "user-1", `{"product_id": 3}`)
+
+		Expect(retry.Code).Should(Equal(http.StatusConflict))
+		Expect(retry.Body).Should(MatchJSON(`{"error":"could not replay request: the first request is still in progress"}`))
+	})
+
+	It("should release the key when the request fails with a server error", func() {
+		fakeNext.ServeHTTPStub = func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, execError(errors.New("connection refused")), http.StatusInternalServerError)
+		}
+
+		Expect(post("4f1c", "user-1", `{"product_id": 3}`).Code).Should(Equal(http.StatusInternalServerError))
+		Expect(post("4f1c", "user-1", `{"product_id": 3}`).Code).Should(Equal(http.StatusInternalServerError))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(2))
+	})
+
+	Context("when the client disconnects", func() {
+
+		var cancel context.CancelFunc
+
+		BeforeEach(func() {
+			// a store honoring the context like the Table one does
+			store := idempotency.Memory(0)
+			fakeStore := new(idempotencyfakes.FakeStore)
+			fakeStore.ReserveStub = store.Reserve
+			fakeStore.CompleteStub = func(ctx context.Context, k idempotency.Key, r idempotency.Response) error {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return store.Complete(ctx, k, r)
+			}
+			fakeStore.ReleaseStub = func(ctx context.Context, k idempotency.Key) error {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return store.Release(ctx, k)
+			}
+			ehandler = Idempotent(fakeSessionReader, fakeNext, WithIdempotency(fakeStore, "sub"))
+
+			ctx, cancel = context.WithCancel(context.Background())
+		})
+
+		It("should still complete the key so the retry is replayed", func() {
+			fakeNext.ServeHTTPStub = func(w http.ResponseWriter, _ *http.Request) {
+				cancel()
+				_, err := w.Write([]byte(`{"rowsAffected":1,"lastInsertedId":42,"error":null}`))
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			post("4f1c", "user-1", `{"product_id": 3}`)
+
+			ctx = context.Background()
+			retry := post("4f1c", "user-1", `{"product_id": 3}`)
+
+			Expect(retry.Code).Should(Equal(http.StatusOK))
+			Expect(retry.Header().Get(IdempotentReplayedHeader)).Should(Equal("true"))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
+		})
+
+		It("should still release the key of a failed request so it can be retried", func() {
+			fakeNext.ServeHTTPStub = func(w http.ResponseWriter, _ *http.Request) {
+				cancel()
+				http.Error(w, `{"error":"could not query the database: context canceled"}`, http.StatusInternalServerError)
+			}
+			post("4f1c", "user-1", `{"product_id": 3}`)
+
+			ctx = context.Background()
+			fakeNext.ServeHTTPStub = nil
+			retry := post("4f1c", "user-1", `{"product_id": 3}`)
+
+			Expect(retry.Code).Should(Equal(http.StatusOK))
+			Expect(retry.Header().Get(IdempotentReplayedHeader)).Should(BeEmpty())
+			Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(2))
+		})
+
+	})
+
+	It("should return BadRequest when the key is too long", func() {
+		key := make([]byte, 256)
+		for i := range key {
+			key[i] = 'k'
+		}
+
+		recorder := post(string(key), "user-1", `{"product_id": 3}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{"error":"could not check idempotency key: longer than 255 characters"}`))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+	})
+
+	It("should return InternalServerError when the store fails", func() {
+		fakeStore := new(idempotencyfakes.FakeStore)
+		fakeStore.ReserveReturns(idempotency.Response{}, false, errors.New("connection refused"))
+		ehandler = Idempotent(fakeSessionReader, fakeNext, WithIdempotency(fakeStore, "sub"))
+
+		recorder := post("4f1c", "user-1", `{"product_id": 3}`)
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{"error":"could not check idempotency key: connection refused"}`))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+	})
+
+})
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/at-silva/ddapi/statement"
+)
+
+// CheckKind makes sure the statement kind matches the given one, so a signed exec cannot be posted to a query
+// endpoint and vice versa. Statements carry their kind in the signed envelope, the kind of legacy requests
+// is inferred from their signed SQL
+func CheckKind(k statement.Kind, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, ok := r.Context().Value(DecodedRequest).(request)
+		if !ok {
+			http.Error(w, errEncode(fmt.Errorf("could not check statement kind: invalid request")), http.StatusInternalServerError)
+			return
+		}
+
+		err := checkKind(k, req)
+		if err != nil {
+			http.Error(w, errEncode(err), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func checkKind(k statement.Kind, req request) error {
+	kind := req.Kind
+	if kind == "" {
+		kind = statement.KindOf(req.SQL)
+	}
+
+	if kind != k {
+		return fmt.Errorf("could not check statement kind: expected %s, got %s", k, kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/at-silva/ddapi/check/checkfakes"
+	"github.com/at-silva/ddapi/db/dbfakes"
+	"github.com/at-silva/ddapi/handler/handlerfakes"
+	"github.com/at-silva/ddapi/session/sessionfakes"
+	"github.com/at-silva/ddapi/statement"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CheckKind", func() {
+
+	var (
+		fakeNext *handlerfakes.FakeHandler
+		recorder *httptest.ResponseRecorder
+		ehandler http.Handler
+	)
+
+	BeforeEach(func() {
+		fakeNext = new(handlerfakes.FakeHandler)
+		recorder = httptest.NewRecorder()
+		ehandler = CheckKind(statement.Query, fakeNext)
+	})
+
+	It("should call the next handler when the statement kind matches", func() {
+		req := request{SQL: "select * from product", Kind: statement.Query}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
+	})
+
+	It("should return Forbidden when the statement kind does not match", func() {
+		req := request{SQL: "select * from product", Kind: statement.Exec}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"error":"could not check statement kind: expected query, got exec"
+		}`))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+	})
+
+	It("should infer the kind of legacy requests from their sql", func() {
+		req := request{SQL: "delete from product where id = :id"}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+	})
+
+	It("should return InternalServerError when it can't find a request in the context", func() {
+		request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+	})
+
+	It("should be enforced by the exec handler chain", func() {
+		e, err := statement.Encode(statement.Statement{Kind: statement.Query, SQL: "delete from product where id = :id", ParamsSchema: []byte(`{}`)})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		fakeDB := new(dbfakes.FakeDB)
+		h := NewExec(fakeDB, new(checkfakes.FakeSignatureChecker), new(sessionfakes.FakeReader), new(checkfakes.FakeParamsChecker))
+		body := `{"statement":"` + e + `","statementSignature":"c2lnbmF0dXJl","params":{"id":1}}`
+		request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/exec", bytes.NewBufferString(body))
+		Expect(err).ShouldNot(HaveOccurred())
+		request.Header.Set("Authorization", "Bearer token")
+
+		h.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"error":"could not check statement kind: expected exec, got query"
+		}`))
+		Expect(fakeDB.NamedExecContextCallCount()).Should(BeZero())
+	})
+
+})
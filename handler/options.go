@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"database/sql"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/idempotency"
+	"github.com/at-silva/ddapi/lint"
+	"github.com/at-silva/ddapi/statement"
+)
+
+type (
+	// Option configures the DDAPI handlers
+	Option func(*options)
+
+	options struct {
+		legacySignatures bool
+		cipher           statement.Cipher
+		registry         statement.Registry
+		linter           lint.Linter
+		txOptions        *sql.TxOptions
+		streaming        bool
+		maxRows          int
+		maxBytes         int64
+		encoders         Encoders
+		pagination       *pagination
+		idempotency      idempotency.Store
+		idempotencyClaim string
+	}
+)
+
+// WithLegacySignatures accepts requests carrying the deprecated sqlSignature/paramsSchemaSignature pair.
+// Both signatures are checked independently, which allows any signed SQL to be paired with any signed params schema,
+// so this should only be enabled while migrating existing frontends to signed statements
+func WithLegacySignatures() Option {
+	return func(o *options) {
+		o.legacySignatures = true
+	}
+}
+
+// WithStatementCipher decrypts the encryptedStatement sent by the frontend before its signature is checked,
+// keeping the SQL and params schema out of the frontend bundle
+func WithStatementCipher(c statement.Cipher) Option {
+	return func(o *options) {
+		o.cipher = c
+	}
+}
+
+// WithRegistry resolves the statementId sent by the frontend through the given registry, registered statements
+// are trusted as they are and don't need to be signed, inline signed statements are still accepted
+func WithRegistry(r statement.Registry) Option {
+	return func(o *options) {
+		o.registry = r
+	}
+}
+
+// WithLinter lints the SQL of every request once its signature is checked, registered statements included,
+// so statements signed before a lint rule was introduced are rejected as well, see lint.Default
+func WithLinter(l lint.Linter) Option {
+	return func(o *options) {
+		o.linter = l
+	}
+}
+
+// WithIsolationLevel runs every statement inside a transaction with the given isolation level, batches included
+func WithIsolationLevel(l sql.IsolationLevel) Option {
+	return func(o *options) {
+		if o.txOptions == nil {
+			o.txOptions = &sql.TxOptions{}
+		}
+		o.txOptions.Isolation = l
+	}
+}
+
+// WithReadOnly runs every statement inside a read-only transaction, it is meant for query handlers as
+// the database rejects any write made through them
+func WithReadOnly() Option {
+	return func(o *options) {
+		if o.txOptions == nil {
+			o.txOptions = &sql.TxOptions{}
+		}
+		o.txOptions.ReadOnly = true
+	}
+}
+
+// WithStreaming makes the query handler write rows to the response as they are scanned, instead of buffering
+// the whole result, as a JSON array or as NDJSON when the client accepts application/x-ndjson. Streams going
+// beyond maxRows rows or maxBytes bytes are terminated with an error marker, zero means no limit
+func WithStreaming(maxRows int, maxBytes int64) Option {
+	return func(o *options) {
+		o.streaming = true
+		o.maxRows = maxRows
+		o.maxBytes = maxBytes
+	}
+}
+
+// WithEncoders encodes the values of query results according to the database type of their columns, see
+// DefaultEncoders. Without encoders, []byte values are turned into strings
+func WithEncoders(e Encoders) Option {
+	return func(o *options) {
+		o.encoders = e
+	}
+}
+
+// WithPagination paginates the statements signed with a {"page": {"keys": [...]}} meta. The SQL reads the
+// page_limit param, holding the page size plus one, and the cursor_<key> params, holding the keys of the last
+// row of the previous page, e.g. "where :cursor_id is null or id > :cursor_id order by id limit :page_limit".
+// Pages hold at most maxPageSize rows, or the pageSize of the request when smaller, and the cursors are
+// signed with s and checked with sc. It panics when maxPageSize is not positive
+func WithPagination(maxPageSize int, s check.Signer, sc check.SignatureChecker) Option {
+	if maxPageSize <= 0 {
+		panic("handler: WithPagination requires a positive max page size")
+	}
+
+	return func(o *options) {
+		o.pagination = &pagination{maxPageSize, s, sc}
+	}
+}
+
+// WithIdempotency makes the exec handler store the first response sent for every Idempotency-Key header in s,
+// replaying it when the request is retried. Keys are scoped to the user identified by the given session claim,
+// e.g. "sub", requests whose session lacks it are rejected. It panics when the claim is empty
+func WithIdempotency(s idempotency.Store, claim string) Option {
+	if claim == "" {
+		panic("handler: WithIdempotency requires a session claim identifying the user")
+	}
+
+	return func(o *options) {
+		o.idempotency = s
+		o.idempotencyClaim = claim
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/at-silva/ddapi/check"
+)
+
+type (
+	// pagination the server side pagination settings, see WithPagination
+	pagination struct {
+		maxPageSize int
+		s           check.Signer
+		sc          check.SignatureChecker
+	}
+
+	// page the pagination of a statement, signed in its meta as {"page": {"keys": ["created_at", "id"]}}, keys
+	// being the result columns identifying the last row of a page
+	page struct {
+		Keys []string `json:"keys"`
+		size int
+	}
+)
+
+const (
+	// pageLimitParam the param holding the page size plus one, so the handler can tell whether there is a next page
+	pageLimitParam = "page_limit"
+
+	// cursorParamPrefix prefixes the params holding the keys of the last row of the previous page
+	cursorParamPrefix = "cursor_"
+)
+
+// paginate returns the page requested for a paginated statement, setting the page_limit and cursor_<key> params,
+// or nil when the statement is not paginated. The cursor keys are nil for the first page
+func paginate(p *pagination, q request, params map[string]interface{}) (*page, int, error) {
+	m, ok := q.Meta["page"]
+	if !ok {
+		return nil, http.StatusOK, nil
+	}
+
+	if p == nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("could not paginate statement: pagination is not supported")
+	}
+
+	var pg page
+	b, err := json.Marshal(m)
+	if err == nil {
+		err = json.Unmarshal(b, &pg)
+	}
+	if err != nil || len(pg.Keys) == 0 {
+		return nil, http.StatusInternalServerError, fmt.Errorf("could not paginate statement: invalid page meta")
+	}
+
+	pg.size = p.maxPageSize
+	if q.PageSize > 0 && q.PageSize < p.maxPageSize {
+		pg.size = q.PageSize
+	}
+
+	cursor := map[string]interface{}{}
+	if q.Cursor != "" {
+		cursor, err = decodeCursor(p.sc, q.SQL, q.Cursor)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+	}
+
+	for _, k := range pg.Keys {
+		params[cursorParamPrefix+k] = cursor[k]
+	}
+	params[pageLimitParam] = pg.size + 1
+
+	return &pg, http.StatusOK, nil
+}
+
+// next trims the extra row fetched to tell whether there is a next page, and returns the cursor to it
+func (pg *page) next(s check.Signer, sql string, res []interface{}) ([]interface{}, string, bool, error) {
+	if pg.size <= 0 {
+		return nil, "", false, fmt.Errorf("could not paginate statement: invalid page size %d", pg.size)
+	}
+
+	if len(res) <= pg.size {
+		return res, "", false, nil
+	}
+
+	res = res[:pg.size]
+	last, _ := res[len(res)-1].(map[string]interface{})
+
+	keys := map[string]interface{}{}
+	for _, k := range pg.Keys {
+		v, ok := last[k]
+		if !ok {
+			return nil, "", false, fmt.Errorf("could not paginate statement: missing key column %s", k)
+		}
+		keys[k] = v
+	}
+
+	c, err := encodeCursor(s, sql, keys)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	return res, c, true, nil
+}
+
+// encodeCursor encodes the keys into an opaque cursor, signed along with the SQL of the statement so it can't be
+// tampered with nor used with another statement
+func encodeCursor(s check.Signer, sql string, keys map[string]interface{}) (string, error) {
+	b, err := json.Marshal(keys)
+	if err != nil {
+		return "", fmt.Errorf("could not encode cursor: %w", err)
+	}
+
+	p := base64.RawURLEncoding.EncodeToString(b)
+	sig, err := s.Sign(cursorPayload(sql, p))
+	if err != nil {
+		return "", fmt.Errorf("could not sign cursor: %w", err)
+	}
+
+	return p + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func decodeCursor(sc check.SignatureChecker, sql, c string) (map[string]interface{}, error) {
+	parts := strings.Split(c, ".")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("could not decode cursor: invalid cursor")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("could not decode cursor: %w", err)
+	}
+
+	err = sc.Check(cursorPayload(sql, parts[0]), sig)
+	if err != nil {
+		return nil, fmt.Errorf("could not validate cursor: %w", err)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("could not decode cursor: %w", err)
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	keys := map[string]interface{}{}
+	err = d.Decode(&keys)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode cursor: %w", err)
+	}
+
+	for k, v := range keys {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+
+		if i, err := n.Int64(); err == nil {
+			keys[k] = i
+		} else if f, err := n.Float64(); err == nil {
+			keys[k] = f
+		}
+	}
+
+	return keys, nil
+}
+
+func cursorPayload(sql, p string) []byte {
+	return []byte(sql + "\n" + p)
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/db/dbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("paginated queryHandler", func() {
+
+	const sql = "select id, name from product where :cursor_id is null or id > :cursor_id order by id limit :page_limit"
+
+	var (
+		fakeDB   *dbfakes.FakeDB
+		fakeRows *dbfakes.FakeRows
+		rows     int
+
+		recorder *httptest.ResponseRecorder
+		opts     []Option
+		serve    func(req request, accept string) queryResponse
+	)
+
+	BeforeEach(func() {
+		fakeDB = new(dbfakes.FakeDB)
+		fakeRows = new(dbfakes.FakeRows)
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		rows = 3
+		fakeRows.NextStub = func() bool {
+			return fakeRows.NextCallCount() <= rows
+		}
+		fakeRows.MapScanStub = func(m map[string]interface{}) error {
+			m["id"] = int64(fakeRows.MapScanCallCount() * 10)
+			m["name"] = "Product"
+			return nil
+		}
+
+		opts = []Option{WithPagination(2, check.Sha256HMACSigner([]byte("secret")), check.Sha256HMAC([]byte("secret")))}
+
+		serve = func(req request, accept string) queryResponse {
+			recorder = httptest.NewRecorder()
+			if req.SQL == "" {
+				req.SQL = sql
+			}
+			if req.Meta == nil {
+				req.Meta = map[string]interface{}{"page": map[string]interface{}{"keys": []interface{}{"id"}}}
+			}
+			serveDecoded(queryHandler{fakeDB, newOptions(opts)}, recorder, req, map[string]interface{}{}, accept)
+
+			var resp queryResponse
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &resp)).Should(Succeed())
+			return resp
+		}
+	})
+
+	It("should return the first page along with the cursor to the next one", func() {
+		resp := serve(request{}, "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(resp.Data).Should(HaveLen(2))
+		Expect(*resp.HasMore).Should(BeTrue())
+		Expect(resp.NextCursor).ShouldNot(BeEmpty())
+
+		_, _, p := fakeDB.NamedQueryContextArgsForCall(0)
+		Expect(p).Should(Equal(map[string]interface{}{"cursor_id": nil, "page_limit": 3}))
+	})
+
+	It("should read the next page from the cursor", func() {
+		cursor := serve(request{}, "").NextCursor
+
+		rows = 1
+		fakeRows = new(dbfakes.FakeRows)
+		fakeRows.NextReturnsOnCall(0, true)
+		fakeRows.MapScanStub = func(m map[string]interface{}) error {
+			m["id"] = int64(30)
+			return nil
+		}
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		resp := serve(request{Cursor: cursor}, "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body).Should(MatchJSON(`{"data": [{"id": 30}], "hasMore": false, "error": null}`))
+		Expect(*resp.HasMore).Should(BeFalse())
+
+		_, _, p := fakeDB.NamedQueryContextArgsForCall(1)
+		Expect(p).Should(Equal(map[string]interface{}{"cursor_id": int64(20), "page_limit": 3}))
+	})
+
+	It("should honor smaller page sizes only", func() {
+		serve(request{PageSize: 1}, "")
+		_, _, p := fakeDB.NamedQueryContextArgsForCall(0)
+		Expect(p).Should(HaveKeyWithValue("page_limit", 2))
+
+		serve(request{PageSize: 100}, "")
+		_, _, p = fakeDB.NamedQueryContextArgsForCall(1)
+		Expect(p).Should(HaveKeyWithValue("page_limit", 3))
+	})
+
+	It("should reject tampered cursors and cursors of other statements", func() {
+		cursor := serve(request{}, "").NextCursor
+
+		serve(request{Cursor: "eyJpZCI6OTk5fQ" + cursor[len("eyJpZCI6MjB9"):]}, "")
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{"error": "could not validate cursor: invalid signature"}`))
+
+		serve(request{SQL: "select id from users where :cursor_id is null or id > :cursor_id limit :page_limit", Cursor: cursor}, "")
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+
+		serve(request{Cursor: "garbage"}, "")
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{"error": "could not decode cursor: invalid cursor"}`))
+
+		Expect(fakeDB.NamedQueryContextCallCount()).Should(Equal(1))
+	})
+
+	It("should fail if the key column is missing from the result", func() {
+		serve(request{Meta: map[string]interface{}{"page": map[string]interface{}{"keys": []interface{}{"created_at"}}}}, "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{"error": "could not paginate statement: missing key column created_at"}`))
+	})
+
+	It("should only paginate json results", func() {
+		serve(request{}, "text/csv")
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{"error": "could not paginate statement: paginated statements can only be read as json"}`))
+	})
+
+	It("should return BadRequest when pagination is not enabled", func() {
+		opts = nil
+
+		serve(request{}, "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{"error": "could not paginate statement: pagination is not supported"}`))
+		Expect(fakeDB.NamedQueryContextCallCount()).Should(BeZero())
+	})
+
+	It("should panic when the max page size is not positive", func() {
+		for _, size := range []int{0, -1} {
+			Expect(func() {
+				WithPagination(size, check.Sha256HMACSigner([]byte("secret")), check.Sha256HMAC([]byte("secret")))
+			}).Should(PanicWith("handler: WithPagination requires a positive max page size"))
+		}
+	})
+
+	It("should fail instead of panicking when the page size is not positive", func() {
+		opts = []Option{func(o *options) {
+			o.pagination = &pagination{0, check.Sha256HMACSigner([]byte("secret")), check.Sha256HMAC([]byte("secret"))}
+		}}
+
+		serve(request{}, "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body.String()).Should(ContainSubstring("could not paginate statement: invalid page size 0"))
+	})
+
+	It("should not paginate statements without a page meta", func() {
+		serve(request{Meta: map[string]interface{}{}}, "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"data": [{"id": 10, "name": "Product"}, {"id": 20, "name": "Product"}, {"id": 30, "name": "Product"}],
+			"error": null
+		}`))
+	})
+
+})
@@ -7,11 +7,16 @@ import (
 	"github.com/at-silva/ddapi/check"
 )
 
-// CheckParams validates the parameters in an incoming request
+// CheckParams validates the parameters in an incoming request, or every element of the bulk parameters
 func CheckParams(pc check.ParamsChecker, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		p, ok := r.Context().Value(DecodedParams).(map[string]interface{})
-		if !ok {
+		var bulk []map[string]interface{}
+		switch p := r.Context().Value(DecodedParams).(type) {
+		case map[string]interface{}:
+			bulk = []map[string]interface{}{p}
+		case []map[string]interface{}:
+			bulk = p
+		default:
 			http.Error(w, errEncode(fmt.Errorf("could not check params: invalid params")), http.StatusInternalServerError)
 			return
 		}
@@ -22,10 +27,15 @@ func CheckParams(pc check.ParamsChecker, next http.Handler) http.Handler {
 			return
 		}
 
-		err := pc.Check(p, req.ParamsSchema)
-		if err != nil {
-			http.Error(w, errEncode(fmt.Errorf("invalid params: %w", err)), http.StatusForbidden)
-			return
+		for i, p := range bulk {
+			err := pc.Check(p, req.ParamsSchema)
+			if err != nil && len(bulk) > 1 {
+				err = fmt.Errorf("row %d: %w", i, err)
+			}
+			if err != nil {
+				http.Error(w, errEncode(fmt.Errorf("invalid params: %w", err)), http.StatusForbidden)
+				return
+			}
 		}
 
 		next.ServeHTTP(w, r)
@@ -101,4 +101,21 @@ var _ = Describe("CheckParams", func() {
 		}`))
 		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
 	})
+	It("should return Forbidden when an element of bulk params is invalid", func() {
+		fakeParamsChecker.CheckReturnsOnCall(1, errors.New("name is required"))
+
+		ctx := context.WithValue(context.Background(), DecodedRequest, request{ParamsSchema: `{}`})
+		ctx = context.WithValue(ctx, DecodedParams, []map[string]interface{}{{"name": "Product1"}, {}})
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+		Expect(recorder.Body).Should(MatchJSON(`{"error":"invalid params: row 1: name is required"}`))
+		Expect(fakeParamsChecker.CheckCallCount()).Should(Equal(2))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+	})
+
 })
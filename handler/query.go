@@ -1,35 +1,46 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/at-silva/ddapi/check"
 	"github.com/at-silva/ddapi/db"
 	"github.com/at-silva/ddapi/session"
+	"github.com/at-silva/ddapi/statement"
 )
 
 type (
 	queryHandler struct {
 		db db.DB
+		o  options
 	}
 
 	queryResponse struct {
-		Data  interface{} `json:"data"`
-		Error *string     `json:"error"`
+		Data       interface{} `json:"data"`
+		NextCursor string      `json:"nextCursor,omitempty"`
+		HasMore    *bool       `json:"hasMore,omitempty"`
+		Error      *string     `json:"error"`
 	}
 )
 
 // NewQuery returns a new DDApi query handler
-func NewQuery(db db.DB, sc check.SignatureChecker, s session.Reader, pc check.ParamsChecker) http.Handler {
+func NewQuery(db db.DB, sc check.SignatureChecker, s session.Reader, pc check.ParamsChecker, opts ...Option) http.Handler {
 	h := DecodeRequest(
 		CheckSignatures(sc,
-			ReadSession(s,
-				CheckParams(pc,
-					queryHandler{
-						db,
-					}))))
+			CheckKind(statement.Query,
+				ReadSession(s,
+					CheckParams(pc,
+						queryHandler{
+							db,
+							newOptions(opts),
+						}))),
+			opts...),
+		opts...)
 
 	return h
 }
@@ -42,39 +53,257 @@ func (h queryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, ok := r.Context().Value(DecodedParams).([]map[string]interface{}); ok {
+		http.Error(w, errEncode(fmt.Errorf("could not query the database: bulk params are only supported by exec statements")), http.StatusBadRequest)
+		return
+	}
+
 	params, ok := r.Context().Value(DecodedParams).(map[string]interface{})
 	if !ok {
 		http.Error(w, errEncode(fmt.Errorf("could not query the database: invalid params")), http.StatusInternalServerError)
 		return
 	}
 
-	rows, err := h.db.NamedQueryContext(r.Context(), q.SQL, params)
+	f, err := queryFormat(r, q)
 	if err != nil {
-		http.Error(w, errEncode(fmt.Errorf("could not query the database: %w", err)), http.StatusInternalServerError)
+		http.Error(w, errEncode(err), http.StatusBadRequest)
 		return
 	}
 
-	var res []interface{}
-	for rows.Next() {
-		row := map[string]interface{}{}
-		err := rows.MapScan(row)
+	pg, code, err := paginate(h.o.pagination, q, params)
+	if err != nil {
+		http.Error(w, errEncode(err), code)
+		return
+	}
+
+	mode, err := resultModeOf(q)
+	if err != nil {
+		http.Error(w, errEncode(err), http.StatusInternalServerError)
+		return
+	}
+
+	sh, err := shapeOf(q)
+	if err != nil {
+		http.Error(w, errEncode(err), http.StatusInternalServerError)
+		return
+	}
+
+	_, isJSON := f.(jsonFormat)
+	if pg != nil && (h.o.streaming || !isJSON) {
+		http.Error(w, errEncode(fmt.Errorf("could not paginate statement: paginated statements can only be read as json")), http.StatusBadRequest)
+		return
+	}
+
+	if pg != nil && (mode != many || sh != nil) {
+		http.Error(w, errEncode(fmt.Errorf("could not paginate statement: paginated statements cannot be shaped")), http.StatusInternalServerError)
+		return
+	}
+
+	if mode != many && (h.o.streaming || !isJSON) {
+		http.Error(w, errEncode(fmt.Errorf("could not shape result: %s results can only be read as json", mode)), http.StatusBadRequest)
+		return
+	}
+
+	if sh != nil && (h.o.streaming || !isJSON) {
+		http.Error(w, errEncode(fmt.Errorf("could not shape result: shaped results can only be read as json")), http.StatusBadRequest)
+		return
+	}
+
+	if h.o.streaming {
+		h.stream(w, r, q, params, f)
+		return
+	}
+
+	if !isJSON {
+		h.write(w, r, q, params, f)
+		return
+	}
+
+	var (
+		res     []interface{}
+		columns []string
+	)
+	err = transact(r.Context(), h.db, h.o, func(e executor) error {
+		var err error
+		res, columns, err = queryStatement(r.Context(), e, q.SQL, params, h.o.encoders)
+		return err
+	})
+	if err != nil {
+		http.Error(w, errEncode(err), http.StatusInternalServerError)
+		return
+	}
+
+	if sh != nil {
+		res, err = sh.apply(res)
 		if err != nil {
-			http.Error(w, errEncode(fmt.Errorf("could not scan rows: %w", err)), http.StatusInternalServerError)
+			http.Error(w, errEncode(err), http.StatusInternalServerError)
 			return
 		}
-		mapBytesToString(row)
-		res = append(res, row)
 	}
 
-	resp, err := json.Marshal(queryResponse{res, nil})
+	var resp queryResponse
+	resp.Data, code, err = mode.shape(res, columns)
+	if err != nil {
+		http.Error(w, errEncode(err), code)
+		return
+	}
+
+	if pg != nil {
+		var hasMore bool
+		resp.Data, resp.NextCursor, hasMore, err = pg.next(h.o.pagination.s, q.SQL, res)
+		if err != nil {
+			http.Error(w, errEncode(err), http.StatusInternalServerError)
+			return
+		}
+		resp.HasMore = &hasMore
+	}
+
+	b, err := json.Marshal(resp)
 	if err != nil {
 		http.Error(w, errEncode(fmt.Errorf("could not serialize result: %w", err)), http.StatusInternalServerError)
 		return
 	}
 
-	_, err = w.Write(resp)
+	_, err = w.Write(b)
 	if err != nil {
 		http.Error(w, errEncode(fmt.Errorf("could not write to the response: %w", err)), http.StatusInternalServerError)
 		return
 	}
 }
+
+// queryStatement returns the rows of a query along with its columns
+func queryStatement(ctx context.Context, e executor, q string, params map[string]interface{}, enc Encoders) ([]interface{}, []string, error) {
+	var (
+		res     []interface{}
+		columns []string
+		ve      valueEncoder
+	)
+
+	begin := func(cts []db.ColumnType) error {
+		columns = columnNames(cts)
+		ve = newValueEncoder(enc, cts)
+		return nil
+	}
+
+	err := scanRows(ctx, e, q, params, begin, mapRow(&ve, func(row map[string]interface{}) error {
+		res = append(res, row)
+		return nil
+	}))
+
+	return res, columns, err
+}
+
+// stream writes the rows to the response as they are scanned, errors found once the stream has started
+// are reported through the trailing error marker of the format
+func (h queryHandler) stream(w http.ResponseWriter, r *http.Request, q request, params map[string]interface{}, f rowFormat) {
+	w.Header().Set("Content-Type", f.contentType())
+
+	s := rowStreamer{w: w, f: f, encoders: h.o.encoders, maxRows: h.o.maxRows, maxBytes: h.o.maxBytes}
+	err := transact(r.Context(), h.db, h.o, func(e executor) error {
+		return scanRows(r.Context(), e, q.SQL, params, s.begin, s.scan)
+	})
+	if err != nil && !s.started {
+		http.Error(w, errEncode(err), http.StatusInternalServerError)
+		return
+	}
+
+	s.end(err)
+}
+
+// write buffers the whole result in the given format before writing it to the response
+func (h queryHandler) write(w http.ResponseWriter, r *http.Request, q request, params map[string]interface{}, f rowFormat) {
+	var buf bytes.Buffer
+	s := rowStreamer{w: &buf, f: f, encoders: h.o.encoders}
+	err := transact(r.Context(), h.db, h.o, func(e executor) error {
+		return scanRows(r.Context(), e, q.SQL, params, s.begin, s.scan)
+	})
+	if err != nil {
+		http.Error(w, errEncode(err), http.StatusInternalServerError)
+		return
+	}
+	s.end(nil)
+
+	w.Header().Set("Content-Type", f.contentType())
+	_, err = w.Write(buf.Bytes())
+	if err != nil {
+		log.Printf("write failed: %v", err)
+	}
+}
+
+// scanRows runs the query, calls begin, when given, with the result set columns and then f to scan every row,
+// the rows are closed on every path
+func scanRows(ctx context.Context, e executor, q string, params map[string]interface{}, begin func(cts []db.ColumnType) error, f func(rows db.Rows) error) error {
+	rows, err := e.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return fmt.Errorf("could not query the database: %w", err)
+	}
+	defer closeRows(rows)
+
+	if begin != nil {
+		cts, err := rows.ColumnTypes()
+		if err != nil {
+			return fmt.Errorf("could not read columns: %w", err)
+		}
+
+		err = begin(cts)
+		if err != nil {
+			return err
+		}
+	}
+
+	for rows.Next() {
+		err := f(rows)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("could not read rows: %w", err)
+	}
+
+	return nil
+}
+
+// mapRow scans rows into maps keyed by column name, the last of duplicate columns wins
+func mapRow(e *valueEncoder, f func(row map[string]interface{}) error) func(rows db.Rows) error {
+	return func(rows db.Rows) error {
+		row := map[string]interface{}{}
+		err := rows.MapScan(row)
+		if err != nil {
+			return fmt.Errorf("could not scan rows: %w", err)
+		}
+
+		err = e.encodeMap(row)
+		if err != nil {
+			return err
+		}
+
+		return f(row)
+	}
+}
+
+// sliceRow scans rows into slices ordered as the result set columns
+func sliceRow(e *valueEncoder, f func(values []interface{}) error) func(rows db.Rows) error {
+	return func(rows db.Rows) error {
+		values, err := rows.SliceScan()
+		if err != nil {
+			return fmt.Errorf("could not scan rows: %w", err)
+		}
+
+		err = e.encodeSlice(values)
+		if err != nil {
+			return err
+		}
+
+		return f(values)
+	}
+}
+
+func closeRows(rows db.Rows) {
+	err := rows.Close()
+	if err != nil {
+		log.Printf("close failed: %v", err)
+	}
+}
@@ -26,7 +26,7 @@ var _ = Describe("queryHandler", func() {
 		fakeRows = new(dbfakes.FakeRows)
 
 		recorder = httptest.NewRecorder()
-		ehandler = queryHandler{fakeDB}
+		ehandler = queryHandler{db: fakeDB}
 	})
 
 	It("should return Ok when a database call succeeds", func() {
@@ -122,6 +122,21 @@ var _ = Describe("queryHandler", func() {
 		Expect(fakeDB.NamedExecContextCallCount()).Should(BeZero())
 	})
 
+	It("should return BadRequest when given bulk params", func() {
+		req := request{SQL: "select * from product where name = :name"}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		ctx = context.WithValue(ctx, DecodedParams, []map[string]interface{}{{"name": "Product1"}})
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{"error":"could not query the database: bulk params are only supported by exec statements"}`))
+		Expect(fakeDB.NamedQueryContextCallCount()).Should(BeZero())
+	})
+
 	It("should return InternalServerErrror when a database call fails", func() {
 		req := request{SQL: "select * from product where name = :name"}
 		ctx := context.WithValue(context.Background(), DecodedRequest, req)
@@ -145,4 +160,94 @@ var _ = Describe("queryHandler", func() {
 		Expect(p).Should(Equal(params))
 	})
 
+	It("should query inside a read-only transaction when configured to", func() {
+		fakeTx := new(dbfakes.FakeTx)
+		fakeDB.BeginTxxReturns(fakeTx, nil)
+		fakeRows.NextReturnsOnCall(0, true)
+		fakeRows.MapScanStub = func(m map[string]interface{}) error {
+			m["name"] = "Product 1"
+			return nil
+		}
+		fakeTx.NamedQueryContextReturns(fakeRows, nil)
+		ehandler = queryHandler{fakeDB, newOptions([]Option{WithReadOnly()})}
+
+		req := request{SQL: "select * from product where name = :name"}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		ctx = context.WithValue(ctx, DecodedParams, map[string]interface{}{"name": "Product1"})
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"data": [{"name":"Product 1"}],
+			"error": null
+		}`))
+		_, txOpts := fakeDB.BeginTxxArgsForCall(0)
+		Expect(txOpts.ReadOnly).Should(BeTrue())
+		Expect(fakeDB.NamedQueryContextCallCount()).Should(BeZero())
+		Expect(fakeTx.CommitCallCount()).Should(Equal(1))
+	})
+
+	It("should close the rows once they are read", func() {
+		req := request{SQL: "select * from product"}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		ctx = context.WithValue(ctx, DecodedParams, map[string]interface{}{})
+
+		fakeRows.NextReturnsOnCall(0, true)
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(fakeRows.CloseCallCount()).Should(Equal(1))
+	})
+
+	It("should close the rows and return InternalServerErrror when a row can't be scanned", func() {
+		req := request{SQL: "select * from product"}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		ctx = context.WithValue(ctx, DecodedParams, map[string]interface{}{})
+
+		fakeRows.NextReturns(true)
+		fakeRows.MapScanReturns(sql.ErrConnDone)
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"error":"could not scan rows: sql: connection is already closed"
+		}`))
+		Expect(fakeRows.CloseCallCount()).Should(Equal(1))
+	})
+
+	It("should return InternalServerErrror when the rows iteration fails", func() {
+		req := request{SQL: "select * from product"}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		ctx = context.WithValue(ctx, DecodedParams, map[string]interface{}{})
+
+		fakeRows.NextReturnsOnCall(0, true)
+		fakeRows.ErrReturns(sql.ErrConnDone)
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"error":"could not read rows: sql: connection is already closed"
+		}`))
+		Expect(fakeRows.CloseCallCount()).Should(Equal(1))
+	})
+
 })
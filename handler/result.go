@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// resultMode the cardinality of a query result, signed in the statement meta as {"result": "one"}
+type resultMode string
+
+const (
+	// many returns every row, the default
+	many resultMode = "many"
+
+	// one returns the single row as an object, or Not Found when there is none
+	one resultMode = "one"
+
+	// maybeOne returns the single row as an object, or null when there is none
+	maybeOne resultMode = "maybeOne"
+
+	// scalar returns the first column of the single row, or null when there is none
+	scalar resultMode = "scalar"
+)
+
+func resultModeOf(q request) (resultMode, error) {
+	v, ok := q.Meta["result"]
+	if !ok {
+		return many, nil
+	}
+
+	m, _ := v.(string)
+	switch resultMode(m) {
+	case many, one, maybeOne, scalar:
+		return resultMode(m), nil
+	default:
+		return "", fmt.Errorf("could not shape result: unknown result mode %v", v)
+	}
+}
+
+// shape returns the rows according to the result mode, failing when there is more than one row for the
+// single row modes
+func (m resultMode) shape(res []interface{}, columns []string) (interface{}, int, error) {
+	if m == many {
+		return res, http.StatusOK, nil
+	}
+
+	if len(res) > 1 {
+		return nil, http.StatusInternalServerError, fmt.Errorf("could not shape result: expected at most one row, got %d", len(res))
+	}
+
+	if len(res) == 0 {
+		if m == one {
+			return nil, http.StatusNotFound, fmt.Errorf("could not shape result: expected one row, got none")
+		}
+		return nil, http.StatusOK, nil
+	}
+
+	if m != scalar {
+		return res[0], http.StatusOK, nil
+	}
+
+	if len(columns) == 0 {
+		return nil, http.StatusInternalServerError, fmt.Errorf("could not shape result: missing columns")
+	}
+
+	row, _ := res[0].(map[string]interface{})
+	return row[columns[0]], http.StatusOK, nil
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/at-silva/ddapi/db"
+	"github.com/at-silva/ddapi/db/dbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("result modes", func() {
+
+	var (
+		fakeDB   *dbfakes.FakeDB
+		fakeRows *dbfakes.FakeRows
+		rows     int
+
+		recorder *httptest.ResponseRecorder
+		serve    func(mode interface{}, accept string)
+	)
+
+	BeforeEach(func() {
+		fakeDB = new(dbfakes.FakeDB)
+		fakeRows = new(dbfakes.FakeRows)
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		var cts []db.ColumnType
+		for _, n := range []string{"total", "name"} {
+			ct := new(dbfakes.FakeColumnType)
+			ct.NameReturns(n)
+			cts = append(cts, ct)
+		}
+		fakeRows.ColumnTypesReturns(cts, nil)
+
+		rows = 1
+		fakeRows.NextStub = func() bool {
+			return fakeRows.NextCallCount() <= rows
+		}
+		fakeRows.MapScanStub = func(m map[string]interface{}) error {
+			m["total"] = 42
+			m["name"] = "Product"
+			return nil
+		}
+
+		recorder = httptest.NewRecorder()
+
+		serve = func(mode interface{}, accept string) {
+			req := request{SQL: "select count(*) total, name from product", Meta: map[string]interface{}{"result": mode}}
+			serveDecoded(queryHandler{db: fakeDB}, recorder, req, map[string]interface{}{}, accept)
+		}
+	})
+
+	It("should return the single row as an object", func() {
+		for _, m := range []string{"one", "maybeOne"} {
+			recorder = httptest.NewRecorder()
+			fakeRows.NextStub = func() bool { return fakeRows.NextCallCount()%2 == 1 }
+
+			serve(m, "")
+
+			Expect(recorder.Code).Should(Equal(http.StatusOK), m)
+			Expect(recorder.Body).Should(MatchJSON(`{"data": {"total": 42, "name": "Product"}, "error": null}`), m)
+		}
+	})
+
+	It("should return the first column of the single row", func() {
+		serve("scalar", "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body).Should(MatchJSON(`{"data": 42, "error": null}`))
+	})
+
+	It("should return null when there is no row", func() {
+		rows = 0
+		for _, m := range []string{"maybeOne", "scalar"} {
+			recorder = httptest.NewRecorder()
+
+			serve(m, "")
+
+			Expect(recorder.Code).Should(Equal(http.StatusOK), m)
+			Expect(recorder.Body).Should(MatchJSON(`{"data": null, "error": null}`), m)
+		}
+	})
+
+	It("should return NotFound when there is no row for one", func() {
+		rows = 0
+
+		serve("one", "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusNotFound))
+		Expect(recorder.Body).Should(MatchJSON(`{"error": "could not shape result: expected one row, got none"}`))
+	})
+
+	It("should fail when there is more than one row", func() {
+		for _, m := range []string{"one", "maybeOne", "scalar"} {
+			recorder = httptest.NewRecorder()
+			fakeRows.NextStub = func() bool { return fakeRows.NextCallCount()%3 != 0 }
+
+			serve(m, "")
+
+			Expect(recorder.Code).Should(Equal(http.StatusInternalServerError), m)
+			Expect(recorder.Body).Should(MatchJSON(`{"error": "could not shape result: expected at most one row, got 2"}`), m)
+		}
+	})
+
+	It("should fail if the result mode is unknown", func() {
+		serve("all", "")
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{"error": "could not shape result: unknown result mode all"}`))
+		Expect(fakeDB.NamedQueryContextCallCount()).Should(BeZero())
+	})
+
+	It("should only shape json results", func() {
+		serve("one", "text/csv")
+
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+		Expect(recorder.Body).Should(MatchJSON(`{"error": "could not shape result: one results can only be read as json"}`))
+	})
+
+})
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/at-silva/ddapi/db"
+	"github.com/at-silva/ddapi/lint"
+)
+
+// returning tells whether an exec statement returns rows, as signed in its meta ({"returning": true}) or else
+// as detected from a RETURNING (PostgreSQL, SQLite, MariaDB) or OUTPUT inserted.*/deleted.* (SQL Server) clause,
+// each clause being looked for only in the dialects supporting it
+func returning(q request, d db.Dialect) bool {
+	if r, ok := q.Meta["returning"].(bool); ok {
+		return r
+	}
+
+	ts, err := lint.Tokenize(q.SQL)
+	if err != nil {
+		return false
+	}
+
+	for i, t := range ts {
+		if t.Depth != 0 {
+			continue
+		}
+
+		if t.Is("returning") && d.Returning() {
+			return true
+		}
+
+		if t.Is("output") && d.Output() && i+1 < len(ts) && (ts[i+1].Is("inserted") || ts[i+1].Is("deleted")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unsupported tells whether LastInsertId is not supported by the driver of an unknown dialect, as with lib/pq and pgx
+func unsupported(err error) bool {
+	return strings.Contains(err.Error(), "not supported")
+}
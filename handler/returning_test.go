@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/at-silva/ddapi/db"
+	"github.com/at-silva/ddapi/db/dbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("returning", func() {
+
+	It("should detect RETURNING and OUTPUT clauses", func() {
+		for q, r := range map[string]bool{
+			"insert into product(name) values(:name) returning id":                   true,
+			"UPDATE product SET name = :name WHERE id = :id RETURNING *":             true,
+			"insert into product(name) output inserted.id values(:name)":             true,
+			"delete from product output deleted.* where id = :id":                    true,
+			"update product set output = :output where id = :id":                     false,
+			"insert into product(name) values(:name)":                                false,
+			"insert into log(msg) select 'returning' from dual":                      false,
+			"with p as (delete from product returning id) insert into log select id": false,
+		} {
+			Expect(returning(request{SQL: q}, db.Unknown)).Should(Equal(r), q)
+		}
+	})
+
+	It("should only look for the clauses supported by the dialect", func() {
+		Expect(returning(request{SQL: "insert into product(name) values(:name) returning id"}, db.Postgres)).Should(BeTrue())
+		Expect(returning(request{SQL: "insert into product(name) output inserted.id values(:name)"}, db.Postgres)).Should(BeFalse())
+		Expect(returning(request{SQL: "insert into product(name) output inserted.id values(:name)"}, db.SQLServer)).Should(BeTrue())
+		Expect(returning(request{SQL: "insert into product(name) values(:name) returning id"}, db.SQLServer)).Should(BeFalse())
+	})
+
+	It("should honor the signed meta", func() {
+		Expect(returning(request{SQL: "call insert_product(:name)", Meta: map[string]interface{}{"returning": true}}, db.Unknown)).Should(BeTrue())
+		Expect(returning(request{SQL: "insert into product(name) values(:name) returning id", Meta: map[string]interface{}{"returning": false}}, db.Unknown)).Should(BeFalse())
+	})
+
+})
+
+var _ = Describe("returning execHandler", func() {
+
+	var (
+		fakeDB   *dbfakes.FakeDB
+		recorder *httptest.ResponseRecorder
+		serve    func(sql string)
+	)
+
+	BeforeEach(func() {
+		fakeDB = new(dbfakes.FakeDB)
+		recorder = httptest.NewRecorder()
+
+		serve = func(sql string) {
+			serveDecoded(execHandler{db: fakeDB}, recorder, request{SQL: sql}, map[string]interface{}{"name": "Product 1"}, "")
+		}
+	})
+
+	It("should run statements with a RETURNING clause as queries", func() {
+		fakeRows := new(dbfakes.FakeRows)
+		fakeRows.NextReturnsOnCall(0, true)
+		fakeRows.MapScanStub = func(m map[string]interface{}) error {
+			m["id"] = 7
+			m["name"] = []byte("Product 1")
+			return nil
+		}
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		serve("insert into product(name) values(:name) returning id, name")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"rowsAffected": 1,
+			"rows": [{"id": 7, "name": "Product 1"}],
+			"error": null
+		}`))
+		Expect(fakeDB.NamedExecContextCallCount()).Should(BeZero())
+		Expect(fakeRows.CloseCallCount()).Should(Equal(1))
+	})
+
+	It("should return an empty list when no row is returned", func() {
+		fakeDB.NamedQueryContextReturns(new(dbfakes.FakeRows), nil)
+
+		serve("delete from product where id = :id returning id")
+
+		Expect(recorder.Body).Should(MatchJSON(`{"rowsAffected": 0, "rows": [], "error": null}`))
+	})
+
+	It("should leave out the last inserted id when the driver doesn't support it", func() {
+		fakeResult := new(dbfakes.FakeResult)
+		fakeResult.RowsAffectedReturns(1, nil)
+		fakeResult.LastInsertIdReturns(0, errors.New("LastInsertId is not supported by this driver"))
+		fakeDB.NamedExecContextReturns(fakeResult, nil)
+
+		serve("insert into product(name) values(:name)")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body).Should(MatchJSON(`{"rowsAffected": 1, "error": null}`))
+	})
+
+	It("should not read the last inserted id on dialects that don't support it", func() {
+		fakeDB.DialectReturns(db.Postgres)
+		fakeResult := new(dbfakes.FakeResult)
+		fakeResult.RowsAffectedReturns(1, nil)
+		fakeDB.NamedExecContextReturns(fakeResult, nil)
+
+		serve("insert into product(name) values(:name)")
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body).Should(MatchJSON(`{"rowsAffected": 1, "error": null}`))
+		Expect(fakeResult.LastInsertIdCallCount()).Should(BeZero())
+	})
+
+	It("should fail when the last inserted id can't be read on dialects supporting it", func() {
+		fakeDB.DialectReturns(db.MySQL)
+		fakeResult := new(dbfakes.FakeResult)
+		fakeResult.RowsAffectedReturns(1, nil)
+		fakeResult.LastInsertIdReturns(0, errors.New("LastInsertId is not supported by this driver"))
+		fakeDB.NamedExecContextReturns(fakeResult, nil)
+
+		serve("insert into product(name) values(:name)")
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{"rowsAffected": 0, "error": "could not read the last inserted id: LastInsertId is not supported by this driver"}`))
+	})
+
+})
@@ -8,28 +8,50 @@ import (
 	"github.com/at-silva/ddapi/session"
 )
 
-// ReadSession copies the session params from the session into the parameters collection
+// ReadSession copies the session params from the session into the parameters collection, or into every
+// element of the bulk parameters collection
 func ReadSession(s session.Reader, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		params, ok := r.Context().Value(DecodedParams).(map[string]interface{})
-		if !ok {
-			http.Error(w, errEncode(fmt.Errorf("could not copy session params: invalid params")), http.StatusInternalServerError)
-			return
-		}
+		var (
+			code int
+			err  error
+		)
 
-		auth := r.Header.Get("Authorization")
-		token := strings.Split(auth, "Bearer ")
-		if len(token) != 2 {
-			http.Error(w, errEncode(fmt.Errorf("could not copy session params: invalid Authorization header")), http.StatusBadRequest)
-			return
+		switch params := r.Context().Value(DecodedParams).(type) {
+		case map[string]interface{}:
+			code, err = readSession(s, r, params)
+		case []map[string]interface{}:
+			claims := map[string]interface{}{}
+			code, err = readSession(s, r, claims)
+			for _, p := range params {
+				for k, v := range claims {
+					p[k] = v
+				}
+			}
+		default:
+			code, err = http.StatusInternalServerError, fmt.Errorf("could not copy session params: invalid params")
 		}
 
-		err := s.Copy(token[1], params)
 		if err != nil {
-			http.Error(w, errEncode(fmt.Errorf("could not copy session params: %w", err)), http.StatusInternalServerError)
+			http.Error(w, errEncode(err), code)
 			return
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+func readSession(s session.Reader, r *http.Request, params map[string]interface{}) (int, error) {
+	auth := r.Header.Get("Authorization")
+	token := strings.Split(auth, "Bearer ")
+	if len(token) != 2 {
+		return http.StatusBadRequest, fmt.Errorf("could not copy session params: invalid Authorization header")
+	}
+
+	err := s.Copy(token[1], params)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("could not copy session params: %w", err)
+	}
+
+	return http.StatusOK, nil
+}
@@ -114,4 +114,29 @@ var _ = Describe("Session", func() {
 
 	})
 
+	It("should copy the session params into every element of bulk params", func() {
+		fakeSessionReader.CopyStub = func(_ string, p map[string]interface{}) error {
+			p["user_id"] = 7
+			return nil
+		}
+
+		params := []map[string]interface{}{{"name": "Product1"}, {"name": "Product2", "user_id": 1}}
+		ctx := context.WithValue(context.Background(), DecodedParams, params)
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		request.Header.Set("Authorization", "Bearer valid-jwt")
+
+		ehandler.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(fakeSessionReader.CopyCallCount()).Should(Equal(1))
+		Expect(params).Should(Equal([]map[string]interface{}{
+			{"name": "Product1", "user_id": 7},
+			{"name": "Product2", "user_id": 7},
+		}))
+		Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
+	})
+
 })
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// shape nests the flat rows of a join, signed in the statement meta as
+// {"shape": {"key": ["id"], "children": {"items": {"key": ["item_id"], "columns": ["item_id", "qty"], "as": {"item_id": "id"}}}}}.
+// Rows are grouped by their key columns, keeping the order of the query, and every group holds the given
+// columns, renamed through as, along with its children collections. Without columns, the root holds every
+// column not used by its children and the children their key columns. Children whose key columns are all
+// null, as left joins without a match produce, are left out
+type shape struct {
+	Key      []string          `json:"key"`
+	Columns  []string          `json:"columns"`
+	As       map[string]string `json:"as"`
+	Children map[string]*shape `json:"children"`
+}
+
+func shapeOf(q request) (*shape, error) {
+	v, ok := q.Meta["shape"]
+	if !ok {
+		return nil, nil
+	}
+
+	var s shape
+	b, err := json.Marshal(v)
+	if err == nil {
+		err = json.Unmarshal(b, &s)
+	}
+	if err != nil || !s.valid() {
+		return nil, fmt.Errorf("could not shape result: invalid shape meta")
+	}
+
+	return &s, nil
+}
+
+func (s *shape) valid() bool {
+	if s == nil || len(s.Key) == 0 {
+		return false
+	}
+
+	for _, c := range s.Children {
+		if !c.valid() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// apply nests the given rows
+func (s *shape) apply(res []interface{}) ([]interface{}, error) {
+	rows := make([]map[string]interface{}, len(res))
+	for i, r := range res {
+		row, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("could not shape result: invalid row")
+		}
+		rows[i] = row
+	}
+
+	return s.nest(rows, true), nil
+}
+
+func (s *shape) nest(rows []map[string]interface{}, root bool) []interface{} {
+	var (
+		keys   []string
+		groups = map[string][]map[string]interface{}{}
+	)
+
+	for _, row := range rows {
+		k, ok := s.key(row)
+		if !ok {
+			continue
+		}
+
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], row)
+	}
+
+	res := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		g := groups[k]
+
+		obj := map[string]interface{}{}
+		for _, c := range s.columns(g[0], root) {
+			name := c
+			if as, ok := s.As[c]; ok {
+				name = as
+			}
+			obj[name] = g[0][c]
+		}
+
+		for name, c := range s.Children {
+			obj[name] = c.nest(g, false)
+		}
+
+		res = append(res, obj)
+	}
+
+	return res
+}
+
+// key returns the grouping key of a row, or false when its key columns are all null
+func (s *shape) key(row map[string]interface{}) (string, bool) {
+	values := make([]interface{}, len(s.Key))
+	null := true
+	for i, k := range s.Key {
+		values[i] = row[k]
+		null = null && row[k] == nil
+	}
+
+	b, _ := json.Marshal(values)
+	return string(b), !null
+}
+
+func (s *shape) columns(row map[string]interface{}, root bool) []string {
+	if len(s.Columns) > 0 {
+		return s.Columns
+	}
+
+	if !root {
+		return s.Key
+	}
+
+	used := map[string]bool{}
+	for _, c := range s.Children {
+		c.used(used)
+	}
+
+	var res []string
+	for c := range row {
+		if !used[c] {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+// used marks the columns of a shape and of its children as used
+func (s *shape) used(u map[string]bool) {
+	for _, k := range s.Key {
+		u[k] = true
+	}
+
+	for _, c := range s.Columns {
+		u[c] = true
+	}
+
+	for _, c := range s.Children {
+		c.used(u)
+	}
+}
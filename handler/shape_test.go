@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/at-silva/ddapi/db/dbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("shape", func() {
+
+	var (
+		rows []map[string]interface{}
+		spec func(s string) *shape
+	)
+
+	BeforeEach(func() {
+		rows = []map[string]interface{}{
+			{"id": 1, "date": "2024-01-01", "item_id": 10, "product": "Apple", "tag": "red"},
+			{"id": 1, "date": "2024-01-01", "item_id": 10, "product": "Apple", "tag": "fruit"},
+			{"id": 1, "date": "2024-01-01", "item_id": 11, "product": "Pear", "tag": nil},
+			{"id": 2, "date": "2024-01-02", "item_id": nil, "product": nil, "tag": nil},
+		}
+
+		spec = func(s string) *shape {
+			var m map[string]interface{}
+			Expect(json.Unmarshal([]byte(s), &m)).Should(Succeed())
+			sh, err := shapeOf(request{Meta: map[string]interface{}{"shape": m}})
+			Expect(err).ShouldNot(HaveOccurred())
+			return sh
+		}
+	})
+
+	It("should nest the children collections", func() {
+		sh := spec(`{
+			"key": ["id"],
+			"children": {
+				"items": {
+					"key": ["item_id"],
+					"columns": ["item_id", "product"],
+					"as": {"item_id": "id"},
+					"children": {"tags": {"key": ["tag"]}}
+				}
+			}
+		}`)
+
+		res := make([]interface{}, len(rows))
+		for i, r := range rows {
+			res[i] = r
+		}
+
+		shaped, err := sh.apply(res)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		b, err := json.Marshal(shaped)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(b).Should(MatchJSON(`[
+			{"id": 1, "date": "2024-01-01", "items": [
+				{"id": 10, "product": "Apple", "tags": [{"tag": "red"}, {"tag": "fruit"}]},
+				{"id": 11, "product": "Pear", "tags": []}
+			]},
+			{"id": 2, "date": "2024-01-02", "items": []}
+		]`))
+	})
+
+	It("should fail if the shape is invalid", func() {
+		for _, s := range []interface{}{
+			"orders",
+			map[string]interface{}{"key": []interface{}{}},
+			map[string]interface{}{"key": []interface{}{"id"}, "children": map[string]interface{}{"items": map[string]interface{}{}}},
+		} {
+			_, err := shapeOf(request{Meta: map[string]interface{}{"shape": s}})
+			Expect(err).Should(MatchError("could not shape result: invalid shape meta"), "%v", s)
+		}
+	})
+
+	It("should be applied by the query handler", func() {
+		fakeRows := new(dbfakes.FakeRows)
+		fakeRows.NextStub = func() bool {
+			return fakeRows.NextCallCount() <= len(rows)
+		}
+		fakeRows.MapScanStub = func(m map[string]interface{}) error {
+			for k, v := range rows[fakeRows.MapScanCallCount()-1] {
+				m[k] = v
+			}
+			return nil
+		}
+		fakeDB := new(dbfakes.FakeDB)
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		req := request{
+			SQL: "select o.id, o.date, i.id item_id, i.product from orders o left join item i on i.order_id = o.id",
+			Meta: map[string]interface{}{
+				"result": "maybeOne",
+				"shape": map[string]interface{}{
+					"key":      []interface{}{"date"},
+					"columns":  []interface{}{"date"},
+					"children": map[string]interface{}{"orders": map[string]interface{}{"key": []interface{}{"id"}, "columns": []interface{}{"id"}}},
+				},
+			},
+		}
+		rows = rows[:3]
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		ctx = context.WithValue(ctx, DecodedParams, map[string]interface{}{})
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		recorder := httptest.NewRecorder()
+		queryHandler{db: fakeDB}.ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"data": {"date": "2024-01-01", "orders": [{"id": 1}]},
+			"error": null
+		}`))
+	})
+
+})
@@ -9,7 +9,9 @@ import (
 )
 
 // CheckSignatures checks the signatures for a given request
-func CheckSignatures(sc check.SignatureChecker, next http.Handler) http.Handler {
+func CheckSignatures(sc check.SignatureChecker, next http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		req, ok := r.Context().Value(DecodedRequest).(request)
 		if !ok {
@@ -17,30 +19,78 @@ func CheckSignatures(sc check.SignatureChecker, next http.Handler) http.Handler
 			return
 		}
 
-		s, err := base64.StdEncoding.DecodeString(req.SQLSignature)
+		code, err := checkSignatures(sc, o, req)
 		if err != nil {
-			http.Error(w, errEncode(fmt.Errorf("could not decode sql signature: %w", err)), http.StatusBadRequest)
+			http.Error(w, errEncode(err), code)
 			return
 		}
 
-		err = sc.Check([]byte(req.SQL), s)
-		if err != nil {
-			http.Error(w, errEncode(fmt.Errorf("could not validate sql signature: %w", err)), http.StatusForbidden)
-			return
-		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-		s, err = base64.StdEncoding.DecodeString(req.ParamsSchemaSignature)
-		if err != nil {
-			http.Error(w, errEncode(fmt.Errorf("could not decode params schema signature: %w", err)), http.StatusBadRequest)
-			return
-		}
+func checkSignatures(sc check.SignatureChecker, o options, req request) (int, error) {
+	var (
+		code = http.StatusOK
+		err  error
+	)
 
-		err = sc.Check([]byte(req.ParamsSchema), s)
-		if err != nil {
-			http.Error(w, errEncode(fmt.Errorf("could not validate params schema signature: %w", err)), http.StatusForbidden)
-			return
-		}
+	switch {
+	case req.registered:
+	case req.Statement != "":
+		code, err = checkStatementSignature(sc, req)
+	case !o.legacySignatures:
+		return http.StatusBadRequest, fmt.Errorf("could not check signatures: missing statement")
+	default:
+		code, err = checkLegacySignatures(sc, req)
+	}
 
-		next.ServeHTTP(w, r)
-	})
+	if err != nil || o.linter == nil {
+		return code, err
+	}
+
+	err = o.linter.Lint(req.SQL)
+	if err != nil {
+		return http.StatusForbidden, fmt.Errorf("could not lint statement: %w", err)
+	}
+
+	return http.StatusOK, nil
+}
+
+func checkStatementSignature(sc check.SignatureChecker, req request) (int, error) {
+	s, err := base64.StdEncoding.DecodeString(req.StatementSignature)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("could not decode statement signature: %w", err)
+	}
+
+	err = sc.Check([]byte(req.Statement), s)
+	if err != nil {
+		return http.StatusForbidden, fmt.Errorf("could not validate statement signature: %w", err)
+	}
+
+	return http.StatusOK, nil
+}
+
+func checkLegacySignatures(sc check.SignatureChecker, req request) (int, error) {
+	s, err := base64.StdEncoding.DecodeString(req.SQLSignature)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("could not decode sql signature: %w", err)
+	}
+
+	err = sc.Check([]byte(req.SQL), s)
+	if err != nil {
+		return http.StatusForbidden, fmt.Errorf("could not validate sql signature: %w", err)
+	}
+
+	s, err = base64.StdEncoding.DecodeString(req.ParamsSchemaSignature)
+	if err != nil {
+		return http.StatusBadRequest, fmt.Errorf("could not decode params schema signature: %w", err)
+	}
+
+	err = sc.Check([]byte(req.ParamsSchema), s)
+	if err != nil {
+		return http.StatusForbidden, fmt.Errorf("could not validate params schema signature: %w", err)
+	}
+
+	return http.StatusOK, nil
 }
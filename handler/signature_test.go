@@ -9,6 +9,7 @@ import (
 
 	"github.com/at-silva/ddapi/check/checkfakes"
 	"github.com/at-silva/ddapi/handler/handlerfakes"
+	"github.com/at-silva/ddapi/lint/lintfakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -29,33 +30,6 @@ var _ = Describe("CheckSignatures", func() {
 		ehandler = CheckSignatures(fakeSignatureChecker, fakeNext)
 	})
 
-	It("should call the next handler when the validation succeeds", func() {
-		req := request{
-			SQL:                   "insert into product(name) values(:name)",
-			SQLSignature:          base64.StdEncoding.EncodeToString([]byte("valid-sql-signature")),
-			ParamsSchema:          `{"type":"object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
-			ParamsSchemaSignature: base64.StdEncoding.EncodeToString([]byte("valid-params-schema-signature")),
-		}
-		ctx := context.WithValue(context.Background(), DecodedRequest, req)
-
-		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
-		Expect(err).ShouldNot(HaveOccurred())
-
-		ehandler.ServeHTTP(recorder, request)
-
-		Expect(recorder.Code).Should(Equal(http.StatusOK))
-
-		s, ss := fakeSignatureChecker.CheckArgsForCall(0)
-		Expect(string(s)).Should(Equal(req.SQL))
-		Expect(string(ss)).Should(Equal("valid-sql-signature"))
-
-		s, ss = fakeSignatureChecker.CheckArgsForCall(1)
-		Expect(string(s)).Should(Equal(req.ParamsSchema))
-		Expect(string(ss)).Should(Equal("valid-params-schema-signature"))
-		Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
-
-	})
-
 	It("should return InternalServerError when it can't find a request in the context", func() {
 		request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/exec", nil)
 		Expect(err).ShouldNot(HaveOccurred())
@@ -69,55 +43,103 @@ var _ = Describe("CheckSignatures", func() {
 		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
 	})
 
-	It("should return BadRequest when the sql signature decoding fails", func() {
-		req := request{
-			SQL:                   "insert into product(name) values(:name)",
-			SQLSignature:          "invalid-signature",
-			ParamsSchema:          `{"type":"object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
-			ParamsSchemaSignature: base64.StdEncoding.EncodeToString([]byte("valid-params-schema-signature")),
-		}
-		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+	Context("with signed statements", func() {
 
-		params := map[string]interface{}{"name": "Product1"}
-		ctx = context.WithValue(ctx, DecodedParams, params)
+		var req request
 
-		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
-		Expect(err).ShouldNot(HaveOccurred())
+		BeforeEach(func() {
+			req = request{
+				SQL:                "insert into product(name) values(:name)",
+				ParamsSchema:       `{"type":"object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
+				Statement:          "valid-statement",
+				StatementSignature: base64.StdEncoding.EncodeToString([]byte("valid-statement-signature")),
+			}
+		})
 
-		ehandler.ServeHTTP(recorder, request)
+		It("should check the statement signature only once", func() {
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
 
-		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
-		Expect(recorder.Body).Should(MatchJSON(`{
-			"error":"could not decode sql signature: illegal base64 data at input byte 7" 
-		}`))
-		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusOK))
+			Expect(fakeSignatureChecker.CheckCallCount()).Should(Equal(1))
+			s, ss := fakeSignatureChecker.CheckArgsForCall(0)
+			Expect(string(s)).Should(Equal(req.Statement))
+			Expect(string(ss)).Should(Equal("valid-statement-signature"))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
+		})
+
+		It("should ignore legacy signatures", func() {
+			req.SQLSignature = "invalid-signature"
+			req.ParamsSchemaSignature = "invalid-signature"
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusOK))
+			Expect(fakeSignatureChecker.CheckCallCount()).Should(Equal(1))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
+		})
+
+		It("should return BadRequest when the statement signature decoding fails", func() {
+			req.StatementSignature = "invalid-signature"
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+			Expect(recorder.Body).Should(MatchJSON(`{
+				"error":"could not decode statement signature: illegal base64 data at input byte 7" 
+			}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
+
+		It("should return Forbidden when the statement validation fails", func() {
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
+			fakeSignatureChecker.CheckReturns(errors.New("invalid signature"))
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+			Expect(recorder.Body).Should(MatchJSON(`{
+				"error":"could not validate statement signature: invalid signature" 
+			}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
 	})
 
-	It("should return BadRequest when the sql signature decoding fails", func() {
+	It("should not check the signatures of registered statements", func() {
 		req := request{
-			SQL:                   "insert into product(name) values(:name)",
-			SQLSignature:          base64.StdEncoding.EncodeToString([]byte("valid-sql-signature")),
-			ParamsSchema:          `{"type":"object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
-			ParamsSchemaSignature: "invalid-signature",
+			SQL:          "select * from product where name = :name",
+			ParamsSchema: `{"type":"object"}`,
+			StatementID:  "products/list",
+			registered:   true,
 		}
 		ctx := context.WithValue(context.Background(), DecodedRequest, req)
 
-		params := map[string]interface{}{"name": "Product1"}
-		ctx = context.WithValue(ctx, DecodedParams, params)
-
-		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/query", nil)
 		Expect(err).ShouldNot(HaveOccurred())
 
 		ehandler.ServeHTTP(recorder, request)
 
-		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
-		Expect(recorder.Body).Should(MatchJSON(`{
-			"error":"could not decode params schema signature: illegal base64 data at input byte 7" 
-		}`))
-		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(fakeSignatureChecker.CheckCallCount()).Should(BeZero())
+		Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
 	})
 
-	It("should return Forbidden when the sql validation fails", func() {
+	It("should return BadRequest when the request has no statement and legacy signatures are disabled", func() {
 		req := request{
 			SQL:                   "insert into product(name) values(:name)",
 			SQLSignature:          base64.StdEncoding.EncodeToString([]byte("valid-sql-signature")),
@@ -126,46 +148,213 @@ var _ = Describe("CheckSignatures", func() {
 		}
 		ctx := context.WithValue(context.Background(), DecodedRequest, req)
 
-		params := map[string]interface{}{"name": "Product1"}
-		ctx = context.WithValue(ctx, DecodedParams, params)
-
-		fakeSignatureChecker.CheckReturns(errors.New("invalid signature"))
-
 		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
 		Expect(err).ShouldNot(HaveOccurred())
 
 		ehandler.ServeHTTP(recorder, request)
 
-		Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+		Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
 		Expect(recorder.Body).Should(MatchJSON(`{
-			"error":"could not validate sql signature: invalid signature" 
+			"error":"could not check signatures: missing statement" 
 		}`))
+		Expect(fakeSignatureChecker.CheckCallCount()).Should(BeZero())
 		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
 	})
 
-	It("should return Forbidden when the params schema validation fails", func() {
-		req := request{
-			SQL:                   "insert into product(name) values(:name)",
-			SQLSignature:          base64.StdEncoding.EncodeToString([]byte("valid-sql-signature")),
-			ParamsSchema:          `{"type":"object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
-			ParamsSchemaSignature: base64.StdEncoding.EncodeToString([]byte("valid-params-schema-signature")),
-		}
-		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+	Context("with a linter", func() {
 
-		params := map[string]interface{}{"name": "Product1"}
-		ctx = context.WithValue(ctx, DecodedParams, params)
+		var (
+			fakeLinter *lintfakes.FakeLinter
+			req        request
+		)
 
-		fakeSignatureChecker.CheckReturnsOnCall(1, errors.New("invalid signature"))
+		BeforeEach(func() {
+			fakeLinter = new(lintfakes.FakeLinter)
+			ehandler = CheckSignatures(fakeSignatureChecker, fakeNext, WithLinter(fakeLinter))
+			req = request{
+				SQL:                "delete from product",
+				ParamsSchema:       `{"type":"object"}`,
+				Statement:          "valid-statement",
+				StatementSignature: base64.StdEncoding.EncodeToString([]byte("valid-statement-signature")),
+			}
+		})
 
-		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
-		Expect(err).ShouldNot(HaveOccurred())
+		It("should lint the statement once its signature is checked", func() {
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
 
-		ehandler.ServeHTTP(recorder, request)
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
 
-		Expect(recorder.Code).Should(Equal(http.StatusForbidden))
-		Expect(recorder.Body).Should(MatchJSON(`{
-			"error":"could not validate params schema signature: invalid signature" 
-		}`))
-		Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusOK))
+			Expect(fakeLinter.LintCallCount()).Should(Equal(1))
+			Expect(fakeLinter.LintArgsForCall(0)).Should(Equal(req.SQL))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
+		})
+
+		It("should lint registered statements", func() {
+			req = request{SQL: "delete from product", StatementID: "products/delete", registered: true}
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
+			fakeLinter.LintReturns(errors.New("bounded-writes: delete without a where clause"))
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+			Expect(recorder.Body).Should(MatchJSON(`{
+				"error":"could not lint statement: bounded-writes: delete without a where clause"
+			}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
+
+		It("should not lint statements with an invalid signature", func() {
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
+			fakeSignatureChecker.CheckReturns(errors.New("invalid signature"))
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+			Expect(fakeLinter.LintCallCount()).Should(BeZero())
+		})
+	})
+
+	Context("with legacy signatures enabled", func() {
+
+		BeforeEach(func() {
+			ehandler = CheckSignatures(fakeSignatureChecker, fakeNext, WithLegacySignatures())
+		})
+
+		It("should call the next handler when the validation succeeds", func() {
+			req := request{
+				SQL:                   "insert into product(name) values(:name)",
+				SQLSignature:          base64.StdEncoding.EncodeToString([]byte("valid-sql-signature")),
+				ParamsSchema:          `{"type":"object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
+				ParamsSchemaSignature: base64.StdEncoding.EncodeToString([]byte("valid-params-schema-signature")),
+			}
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusOK))
+
+			s, ss := fakeSignatureChecker.CheckArgsForCall(0)
+			Expect(string(s)).Should(Equal(req.SQL))
+			Expect(string(ss)).Should(Equal("valid-sql-signature"))
+
+			s, ss = fakeSignatureChecker.CheckArgsForCall(1)
+			Expect(string(s)).Should(Equal(req.ParamsSchema))
+			Expect(string(ss)).Should(Equal("valid-params-schema-signature"))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(Equal(1))
+
+		})
+
+		It("should return BadRequest when the sql signature decoding fails", func() {
+			req := request{
+				SQL:                   "insert into product(name) values(:name)",
+				SQLSignature:          "invalid-signature",
+				ParamsSchema:          `{"type":"object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
+				ParamsSchemaSignature: base64.StdEncoding.EncodeToString([]byte("valid-params-schema-signature")),
+			}
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
+
+			params := map[string]interface{}{"name": "Product1"}
+			ctx = context.WithValue(ctx, DecodedParams, params)
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+			Expect(recorder.Body).Should(MatchJSON(`{
+				"error":"could not decode sql signature: illegal base64 data at input byte 7" 
+			}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
+
+		It("should return BadRequest when the sql signature decoding fails", func() {
+			req := request{
+				SQL:                   "insert into product(name) values(:name)",
+				SQLSignature:          base64.StdEncoding.EncodeToString([]byte("valid-sql-signature")),
+				ParamsSchema:          `{"type":"object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
+				ParamsSchemaSignature: "invalid-signature",
+			}
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
+
+			params := map[string]interface{}{"name": "Product1"}
+			ctx = context.WithValue(ctx, DecodedParams, params)
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusBadRequest))
+			Expect(recorder.Body).Should(MatchJSON(`{
+				"error":"could not decode params schema signature: illegal base64 data at input byte 7" 
+			}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
+
+		It("should return Forbidden when the sql validation fails", func() {
+			req := request{
+				SQL:                   "insert into product(name) values(:name)",
+				SQLSignature:          base64.StdEncoding.EncodeToString([]byte("valid-sql-signature")),
+				ParamsSchema:          `{"type":"object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
+				ParamsSchemaSignature: base64.StdEncoding.EncodeToString([]byte("valid-params-schema-signature")),
+			}
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
+
+			params := map[string]interface{}{"name": "Product1"}
+			ctx = context.WithValue(ctx, DecodedParams, params)
+
+			fakeSignatureChecker.CheckReturns(errors.New("invalid signature"))
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+			Expect(recorder.Body).Should(MatchJSON(`{
+				"error":"could not validate sql signature: invalid signature" 
+			}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
+
+		It("should return Forbidden when the params schema validation fails", func() {
+			req := request{
+				SQL:                   "insert into product(name) values(:name)",
+				SQLSignature:          base64.StdEncoding.EncodeToString([]byte("valid-sql-signature")),
+				ParamsSchema:          `{"type":"object", "required": ["name"], "properties": {"name": {"type": "string"}}}`,
+				ParamsSchemaSignature: base64.StdEncoding.EncodeToString([]byte("valid-params-schema-signature")),
+			}
+			ctx := context.WithValue(context.Background(), DecodedRequest, req)
+
+			params := map[string]interface{}{"name": "Product1"}
+			ctx = context.WithValue(ctx, DecodedParams, params)
+
+			fakeSignatureChecker.CheckReturnsOnCall(1, errors.New("invalid signature"))
+
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, "/exec", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ehandler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).Should(Equal(http.StatusForbidden))
+			Expect(recorder.Body).Should(MatchJSON(`{
+				"error":"could not validate params schema signature: invalid signature" 
+			}`))
+			Expect(fakeNext.ServeHTTPCallCount()).Should(BeZero())
+		})
 	})
 })
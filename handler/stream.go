@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/at-silva/ddapi/db"
+)
+
+// rowStreamer writes rows as they are scanned, and terminates the stream once the row or byte limits are exceeded
+type rowStreamer struct {
+	w        io.Writer
+	f        rowFormat
+	encoders Encoders
+	maxRows  int
+	maxBytes int64
+
+	cts     []db.ColumnType
+	columns []string
+	ve      valueEncoder
+	rows    int
+	bytes   int64
+	started bool
+}
+
+func (s *rowStreamer) begin(cts []db.ColumnType) error {
+	s.cts = cts
+	s.columns = columnNames(cts)
+	s.ve = newValueEncoder(s.encoders, cts)
+
+	return s.start()
+}
+
+func (s *rowStreamer) start() error {
+	s.started = true
+
+	b, err := s.f.begin(s.cts)
+	if err != nil {
+		return fmt.Errorf("could not serialize result: %w", err)
+	}
+
+	return s.write(b)
+}
+
+// scan scans the current row as a slice for the formats preserving duplicate columns, as a map otherwise
+func (s *rowStreamer) scan(rows db.Rows) error {
+	if f, ok := s.f.(sliceFormat); ok {
+		return sliceRow(&s.ve, func(values []interface{}) error {
+			return s.writeRow(f.values(s.rows, values))
+		})(rows)
+	}
+
+	return mapRow(&s.ve, func(row map[string]interface{}) error {
+		return s.writeRow(s.f.row(s.rows, s.columns, row))
+	})(rows)
+}
+
+// writeRow writes a serialized row, unless it goes beyond the row or byte limits
+func (s *rowStreamer) writeRow(b []byte, err error) error {
+	if s.maxRows > 0 && s.rows >= s.maxRows {
+		return fmt.Errorf("could not stream rows: row limit of %d exceeded", s.maxRows)
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not serialize result: %w", err)
+	}
+
+	if s.maxBytes > 0 && s.bytes+int64(len(b)) > s.maxBytes {
+		return fmt.Errorf("could not stream rows: byte limit of %d exceeded", s.maxBytes)
+	}
+
+	s.rows++
+	return s.write(b)
+}
+
+// end terminates the stream, writing the error marker when err is not nil
+func (s *rowStreamer) end(err error) {
+	if !s.started {
+		werr := s.start()
+		if werr != nil {
+			log.Printf("write failed: %v", werr)
+			return
+		}
+	}
+
+	werr := s.write(s.f.end(err))
+	if werr != nil {
+		log.Printf("write failed: %v", werr)
+	}
+}
+
+// write writes to the response and flushes it, so rows reach the client as they are scanned instead of
+// waiting for the response buffer to fill up
+func (s *rowStreamer) write(b []byte) error {
+	n, err := s.w.Write(b)
+	s.bytes += int64(n)
+	if err != nil {
+		return fmt.Errorf("could not write to the response: %w", err)
+	}
+
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	return nil
+}
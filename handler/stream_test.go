@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/at-silva/ddapi/db/dbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// flushRecorder records the body written so far on every flush
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed []string
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushed = append(r.flushed, r.Body.String())
+	r.ResponseRecorder.Flush()
+}
+
+var _ = Describe("streaming queryHandler", func() {
+
+	var (
+		fakeDB   *dbfakes.FakeDB
+		fakeRows *dbfakes.FakeRows
+
+		recorder *httptest.ResponseRecorder
+		serve    func(accept string, opts ...Option)
+	)
+
+	BeforeEach(func() {
+		fakeDB = new(dbfakes.FakeDB)
+		fakeRows = new(dbfakes.FakeRows)
+		fakeDB.NamedQueryContextReturns(fakeRows, nil)
+
+		for i := 0; i < 3; i++ {
+			fakeRows.NextReturnsOnCall(i, true)
+		}
+		n := 0
+		fakeRows.MapScanStub = func(m map[string]interface{}) error {
+			n++
+			m["id"] = n
+			return nil
+		}
+
+		recorder = httptest.NewRecorder()
+
+		serve = func(accept string, opts ...Option) {
+			serveDecoded(queryHandler{fakeDB, newOptions(opts)}, recorder, request{SQL: "select id from product"}, map[string]interface{}{}, accept)
+		}
+	})
+
+	It("should stream rows as a JSON array", func() {
+		serve("application/json", WithStreaming(0, 0))
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).Should(Equal("application/json"))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"data": [{"id": 1}, {"id": 2}, {"id": 3}],
+			"error": null
+		}`))
+		Expect(fakeRows.CloseCallCount()).Should(Equal(1))
+	})
+
+	It("should stream an empty JSON array", func() {
+		fakeRows.NextReturnsOnCall(0, false)
+
+		serve("application/json", WithStreaming(0, 0))
+
+		Expect(recorder.Body).Should(MatchJSON(`{"data": [], "error": null}`))
+	})
+
+	It("should stream rows as NDJSON", func() {
+		serve("application/x-ndjson", WithStreaming(0, 0))
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).Should(Equal("application/x-ndjson"))
+		Expect(recorder.Body.String()).Should(Equal("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"))
+	})
+
+	It("should flush the response after every row", func() {
+		fr := &flushRecorder{ResponseRecorder: recorder}
+		req := request{SQL: "select id from product"}
+		ctx := context.WithValue(context.Background(), DecodedRequest, req)
+		ctx = context.WithValue(ctx, DecodedParams, map[string]interface{}{})
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, "/query", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		request.Header.Set("Accept", "application/x-ndjson")
+
+		queryHandler{fakeDB, newOptions([]Option{WithStreaming(0, 0)})}.ServeHTTP(fr, request)
+
+		Expect(fr.flushed).Should(Equal([]string{
+			"",
+			"{\"id\":1}\n",
+			"{\"id\":1}\n{\"id\":2}\n",
+			"{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n",
+			"{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n",
+		}))
+	})
+
+	It("should terminate the stream once the row limit is exceeded", func() {
+		serve("application/json", WithStreaming(2, 0))
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"data": [{"id": 1}, {"id": 2}],
+			"error": "could not stream rows: row limit of 2 exceeded"
+		}`))
+		Expect(fakeRows.CloseCallCount()).Should(Equal(1))
+	})
+
+	It("should terminate the stream once the byte limit is exceeded", func() {
+		serve("application/x-ndjson", WithStreaming(0, 20))
+
+		Expect(recorder.Body.String()).Should(Equal("{\"id\":1}\n{\"id\":2}\n{\"error\":\"could not stream rows: byte limit of 20 exceeded\"}\n"))
+	})
+
+	It("should report iteration errors through the error marker", func() {
+		fakeRows.ErrReturns(sql.ErrConnDone)
+
+		serve("application/x-ndjson", WithStreaming(0, 0))
+
+		Expect(recorder.Code).Should(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).Should(HaveSuffix("{\"error\":\"could not read rows: sql: connection is already closed\"}\n"))
+	})
+
+	It("should return InternalServerErrror when the query fails", func() {
+		fakeDB.NamedQueryContextReturns(nil, sql.ErrConnDone)
+
+		serve("application/x-ndjson", WithStreaming(0, 0))
+
+		Expect(recorder.Code).Should(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"error":"could not query the database: sql: connection is already closed"
+		}`))
+	})
+
+	It("should roll back the transaction when the stream is terminated", func() {
+		fakeTx := new(dbfakes.FakeTx)
+		fakeTx.NamedQueryContextReturns(fakeRows, nil)
+		fakeDB.BeginTxxReturns(fakeTx, nil)
+
+		serve("application/json", WithStreaming(1, 0), WithReadOnly())
+
+		Expect(recorder.Body).Should(MatchJSON(`{
+			"data": [{"id": 1}],
+			"error": "could not stream rows: row limit of 1 exceeded"
+		}`))
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+		Expect(fakeTx.CommitCallCount()).Should(BeZero())
+	})
+
+})
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/at-silva/ddapi/db"
+)
+
+// executor is satisfied by both db.DB and db.Tx
+type executor interface {
+	namedExecer
+	NamedQueryContext(ctx context.Context, query string, arg interface{}) (db.Rows, error)
+}
+
+// transact runs f inside a transaction when the handler is configured with an isolation level or as read-only,
+// the transaction is committed when f succeeds and rolled back otherwise. Without any of these options f runs
+// straight against the database
+func transact(ctx context.Context, d db.DB, o options, f func(e executor) error) error {
+	if o.txOptions == nil {
+		return f(d)
+	}
+
+	tx, err := d.BeginTxx(ctx, o.txOptions)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+
+	err = f(tx)
+	if err != nil {
+		rollback(tx)
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
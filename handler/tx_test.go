@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/at-silva/ddapi/db/dbfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("transact", func() {
+
+	var (
+		fakeDB *dbfakes.FakeDB
+		fakeTx *dbfakes.FakeTx
+		o      options
+	)
+
+	BeforeEach(func() {
+		fakeDB = new(dbfakes.FakeDB)
+		fakeTx = new(dbfakes.FakeTx)
+		fakeDB.BeginTxxReturns(fakeTx, nil)
+		o = newOptions([]Option{WithIsolationLevel(sql.LevelSerializable), WithReadOnly()})
+	})
+
+	It("should run straight against the database without transaction options", func() {
+		var got executor
+		err := transact(context.Background(), fakeDB, newOptions(nil), func(e executor) error {
+			got = e
+			return nil
+		})
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(got).Should(BeIdenticalTo(fakeDB))
+		Expect(fakeDB.BeginTxxCallCount()).Should(BeZero())
+	})
+
+	It("should commit the transaction when f succeeds", func() {
+		var got executor
+		err := transact(context.Background(), fakeDB, o, func(e executor) error {
+			got = e
+			return nil
+		})
+
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(got).Should(BeIdenticalTo(fakeTx))
+		_, txOpts := fakeDB.BeginTxxArgsForCall(0)
+		Expect(txOpts).Should(Equal(&sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}))
+		Expect(fakeTx.CommitCallCount()).Should(Equal(1))
+		Expect(fakeTx.RollbackCallCount()).Should(BeZero())
+	})
+
+	It("should roll back the transaction when f fails", func() {
+		err := transact(context.Background(), fakeDB, o, func(e executor) error {
+			return errors.New("could not query the database: deadlock")
+		})
+
+		Expect(err).Should(MatchError("could not query the database: deadlock"))
+		Expect(fakeTx.RollbackCallCount()).Should(Equal(1))
+		Expect(fakeTx.CommitCallCount()).Should(BeZero())
+	})
+
+	It("should fail if the transaction can't begin", func() {
+		fakeDB.BeginTxxReturns(nil, errors.New("too many connections"))
+
+		err := transact(context.Background(), fakeDB, o, func(e executor) error {
+			Fail("f should not be called")
+			return nil
+		})
+
+		Expect(err).Should(MatchError("could not begin transaction: too many connections"))
+	})
+
+	It("should fail if the transaction can't be committed", func() {
+		fakeTx.CommitReturns(errors.New("serialization failure"))
+
+		err := transact(context.Background(), fakeDB, o, func(e executor) error {
+			return nil
+		})
+
+		Expect(err).Should(MatchError("could not commit transaction: serialization failure"))
+	})
+
+})
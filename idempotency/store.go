@@ -0,0 +1,187 @@
+//Package idempotency contains stores keeping the first response sent for every idempotency key.
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+type (
+	// Key identifies a request by the subject of the session sending it and its Idempotency-Key header
+	Key struct {
+		Subject string
+		Key     string
+	}
+
+	// Response the response stored for a key, Code is zero while the first request is in flight
+	Response struct {
+		Fingerprint string
+		Code        int
+		Body        []byte
+	}
+
+	// Store keeps the first response sent for every key
+	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Store
+	Store interface {
+		// Reserve stores r unless k is taken, in which case the stored response is returned along with false
+		Reserve(ctx context.Context, k Key, r Response) (Response, bool, error)
+		// Complete replaces the response stored for k
+		Complete(ctx context.Context, k Key, r Response) error
+		// Release forgets k, so the request can be retried
+		Release(ctx context.Context, k Key) error
+	}
+
+	// DB represents the subset of a sqlx.DB used by the Table store
+	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . DB
+	DB interface {
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+		GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+		Rebind(query string) string
+	}
+
+	memory struct {
+		mu      sync.Mutex
+		ttl     time.Duration
+		entries map[Key]entry
+	}
+
+	entry struct {
+		r       Response
+		expires time.Time
+	}
+
+	tableStore struct {
+		db                              DB
+		reserve, get, complete, release string
+	}
+
+	tableRow struct {
+		Fingerprint string `db:"fingerprint"`
+		Code        int    `db:"code"`
+		Body        string `db:"body"`
+	}
+)
+
+// Memory returns an in-memory store forgetting keys ttl after they were reserved, zero means never
+func Memory(ttl time.Duration) Store {
+	return &memory{ttl: ttl, entries: map[Key]entry{}}
+}
+
+// Reserve stores r unless k is taken
+func (m *memory) Reserve(_ context.Context, k Key, r Response) (Response, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	e, ok := m.entries[k]
+	if ok && (e.expires.IsZero() || now.Before(e.expires)) {
+		return e.r, false, nil
+	}
+
+	e = entry{r: r}
+	if m.ttl > 0 {
+		e.expires = now.Add(m.ttl)
+	}
+	m.entries[k] = e
+
+	m.expire(now)
+	return r, true, nil
+}
+
+// Complete replaces the response stored for k
+func (m *memory) Complete(_ context.Context, k Key, r Response) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[k]
+	if !ok {
+		return fmt.Errorf("could not complete idempotency key %s: key not reserved", k.Key)
+	}
+
+	e.r = r
+	m.entries[k] = e
+	return nil
+}
+
+// Release forgets k
+func (m *memory) Release(_ context.Context, k Key) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, k)
+	return nil
+}
+
+// expire drops the expired entries, it must be called with the lock held
+func (m *memory) expire(now time.Time) {
+	for k, e := range m.entries {
+		if !e.expires.IsZero() && !now.Before(e.expires) {
+			delete(m.entries, k)
+		}
+	}
+}
+
+// Table returns a store backed by a database table with a primary key on the subject and idempotency_key columns,
+// along with the fingerprint, code, body and created_at columns. Rows are never deleted once completed, old ones
+// can be purged by their created_at
+func Table(db DB, table string) (Store, error) {
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	return tableStore{
+		db:       db,
+		reserve:  db.Rebind("insert into " + table + " (subject, idempotency_key, fingerprint, code, body, created_at) values (?, ?, ?, ?, ?, ?)"),
+		get:      db.Rebind("select fingerprint, code, body from " + table + " where subject = ? and idempotency_key = ?"),
+		complete: db.Rebind("update " + table + " set fingerprint = ?, code = ?, body = ? where subject = ? and idempotency_key = ?"),
+		release:  db.Rebind("delete from " + table + " where subject = ? and idempotency_key = ?"),
+	}, nil
+}
+
+// Reserve inserts r unless a row already exists for k, the insert failing on the primary key makes
+// concurrent reservations safe
+func (t tableStore) Reserve(ctx context.Context, k Key, r Response) (Response, bool, error) {
+	_, err := t.db.ExecContext(ctx, t.reserve, k.Subject, k.Key, r.Fingerprint, r.Code, string(r.Body), time.Now().UTC())
+	if err == nil {
+		return r, true, nil
+	}
+
+	var row tableRow
+	gerr := t.db.GetContext(ctx, &row, t.get, k.Subject, k.Key)
+	if errors.Is(gerr, sql.ErrNoRows) {
+		return Response{}, false, fmt.Errorf("could not reserve idempotency key %s: %w", k.Key, err)
+	}
+
+	if gerr != nil {
+		return Response{}, false, fmt.Errorf("could not read idempotency key %s: %w", k.Key, gerr)
+	}
+
+	return Response{Fingerprint: row.Fingerprint, Code: row.Code, Body: []byte(row.Body)}, false, nil
+}
+
+// Complete updates the response stored for k
+func (t tableStore) Complete(ctx context.Context, k Key, r Response) error {
+	_, err := t.db.ExecContext(ctx, t.complete, r.Fingerprint, r.Code, string(r.Body), k.Subject, k.Key)
+	if err != nil {
+		return fmt.Errorf("could not complete idempotency key %s: %w", k.Key, err)
+	}
+
+	return nil
+}
+
+// Release deletes the row of k
+func (t tableStore) Release(ctx context.Context, k Key) error {
+	_, err := t.db.ExecContext(ctx, t.release, k.Subject, k.Key)
+	if err != nil {
+		return fmt.Errorf("could not release idempotency key %s: %w", k.Key, err)
+	}
+
+	return nil
+}
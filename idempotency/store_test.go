@@ -0,0 +1,169 @@
+package idempotency_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/at-silva/ddapi/idempotency"
+	"github.com/at-silva/ddapi/idempotency/idempotencyfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Store", func() {
+
+	var (
+		ctx = context.Background()
+		k   = idempotency.Key{Subject: "7", Key: "4f1c"}
+	)
+
+	Describe("Memory", func() {
+
+		It("should return the stored response once a key is taken", func() {
+			s := idempotency.Memory(0)
+
+			_, ok, err := s.Reserve(ctx, k, idempotency.Response{Fingerprint: "a"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ok).Should(BeTrue())
+
+			Expect(s.Complete(ctx, k, idempotency.Response{Fingerprint: "a", Code: 200, Body: []byte(`{}`)})).Should(Succeed())
+
+			r, ok, err := s.Reserve(ctx, k, idempotency.Response{Fingerprint: "b"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ok).Should(BeFalse())
+			Expect(r).Should(Equal(idempotency.Response{Fingerprint: "a", Code: 200, Body: []byte(`{}`)}))
+		})
+
+		It("should keep the keys of different subjects apart", func() {
+			s := idempotency.Memory(0)
+
+			_, ok, err := s.Reserve(ctx, k, idempotency.Response{Fingerprint: "a"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ok).Should(BeTrue())
+
+			_, ok, err = s.Reserve(ctx, idempotency.Key{Subject: "8", Key: k.Key}, idempotency.Response{Fingerprint: "a"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ok).Should(BeTrue())
+		})
+
+		It("should forget released keys", func() {
+			s := idempotency.Memory(0)
+
+			_, _, err := s.Reserve(ctx, k, idempotency.Response{Fingerprint: "a"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(s.Release(ctx, k)).Should(Succeed())
+
+			_, ok, err := s.Reserve(ctx, k, idempotency.Response{Fingerprint: "b"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ok).Should(BeTrue())
+		})
+
+		It("should forget expired keys", func() {
+			s := idempotency.Memory(time.Millisecond)
+
+			_, _, err := s.Reserve(ctx, k, idempotency.Response{Fingerprint: "a"})
+			Expect(err).ShouldNot(HaveOccurred())
+			time.Sleep(5 * time.Millisecond)
+
+			_, ok, err := s.Reserve(ctx, k, idempotency.Response{Fingerprint: "b"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ok).Should(BeTrue())
+		})
+
+		It("should fail to complete a key that isn't reserved", func() {
+			err := idempotency.Memory(0).Complete(ctx, k, idempotency.Response{Code: 200})
+			Expect(err).Should(MatchError("could not complete idempotency key 4f1c: key not reserved"))
+		})
+
+	})
+
+	Describe("Table", func() {
+
+		var fakeDB *idempotencyfakes.FakeDB
+
+		BeforeEach(func() {
+			fakeDB = new(idempotencyfakes.FakeDB)
+			fakeDB.RebindStub = func(q string) string { return q }
+		})
+
+		It("should insert a row to reserve a key", func() {
+			s, err := idempotency.Table(fakeDB, "ddapi.idempotency_keys")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, ok, err := s.Reserve(ctx, k, idempotency.Response{Fingerprint: "a"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ok).Should(BeTrue())
+
+			_, q, args := fakeDB.ExecContextArgsForCall(0)
+			Expect(q).Should(Equal("insert into ddapi.idempotency_keys (subject, idempotency_key, fingerprint, code, body, created_at) values (?, ?, ?, ?, ?, ?)"))
+			Expect(args[:5]).Should(Equal([]interface{}{"7", "4f1c", "a", 0, ""}))
+			Expect(fakeDB.GetContextCallCount()).Should(BeZero())
+		})
+
+		It("should return the stored row when the key is taken", func() {
+			fakeDB.ExecContextReturns(nil, errors.New("duplicate key value violates unique constraint"))
+			fakeDB.GetContextStub = func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+				Expect(json.Unmarshal([]byte(`{"Fingerprint":"a","Code":200,"Body":"{}"}`), dest)).Should(Succeed())
+				return nil
+			}
+
+			s, err := idempotency.Table(fakeDB, "idempotency_keys")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			r, ok, err := s.Reserve(ctx, k, idempotency.Response{Fingerprint: "b"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ok).Should(BeFalse())
+			Expect(r).Should(Equal(idempotency.Response{Fingerprint: "a", Code: 200, Body: []byte(`{}`)}))
+
+			_, _, q, args := fakeDB.GetContextArgsForCall(0)
+			Expect(q).Should(Equal("select fingerprint, code, body from idempotency_keys where subject = ? and idempotency_key = ?"))
+			Expect(args).Should(Equal([]interface{}{"7", "4f1c"}))
+		})
+
+		It("should fail when the insert fails for another reason", func() {
+			fakeDB.ExecContextReturns(nil, errors.New("connection refused"))
+			fakeDB.GetContextReturns(sql.ErrNoRows)
+
+			s, err := idempotency.Table(fakeDB, "idempotency_keys")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, _, err = s.Reserve(ctx, k, idempotency.Response{Fingerprint: "a"})
+			Expect(err).Should(MatchError("could not reserve idempotency key 4f1c: connection refused"))
+		})
+
+		It("should update the row to complete a key", func() {
+			s, err := idempotency.Table(fakeDB, "idempotency_keys")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(s.Complete(ctx, k, idempotency.Response{Fingerprint: "a", Code: 200, Body: []byte(`{}`)})).Should(Succeed())
+
+			_, q, args := fakeDB.ExecContextArgsForCall(0)
+			Expect(q).Should(Equal("update idempotency_keys set fingerprint = ?, code = ?, body = ? where subject = ? and idempotency_key = ?"))
+			Expect(args).Should(Equal([]interface{}{"a", 200, "{}", "7", "4f1c"}))
+		})
+
+		It("should delete the row to release a key", func() {
+			fakeDB.ExecContextReturns(nil, errors.New("connection refused"))
+
+			s, err := idempotency.Table(fakeDB, "idempotency_keys")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			err = s.Release(ctx, k)
+			Expect(err).Should(MatchError("could not release idempotency key 4f1c: connection refused"))
+
+			_, q, _ := fakeDB.ExecContextArgsForCall(0)
+			Expect(q).Should(Equal("delete from idempotency_keys where subject = ? and idempotency_key = ?"))
+		})
+
+		It("should reject invalid table names", func() {
+			_, err := idempotency.Table(fakeDB, "keys; drop table users")
+			Expect(err).Should(MatchError(`invalid table name "keys; drop table users"`))
+		})
+
+	})
+
+})
@@ -0,0 +1,106 @@
+//Package lint contains static checks applied to statements before they are signed or executed
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// Linter represents a statement linter
+	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Linter
+	Linter interface {
+		Lint(sql string) error
+	}
+
+	// Lint linter function type
+	Lint func(sql string) error
+
+	// Rule checks the tokens of a statement, reporting every problem found
+	Rule func(ts []Token) []Problem
+
+	// Problem a rule violation
+	Problem struct {
+		Rule    string
+		Message string
+	}
+
+	// Problems the error returned by linters built with Rules
+	Problems []Problem
+)
+
+// Lint lints the given statement
+func (f Lint) Lint(sql string) error {
+	return f(sql)
+}
+
+func (p Problem) String() string {
+	return p.Rule + ": " + p.Message
+}
+
+func (p Problems) Error() string {
+	s := make([]string, len(p))
+	for i := range p {
+		s[i] = p[i].String()
+	}
+
+	return strings.Join(s, "; ")
+}
+
+// Rules returns a linter applying every given rule, problems are reported as Problems
+func Rules(rules ...Rule) Lint {
+	return func(sql string) error {
+		ts, err := Tokenize(sql)
+		if err != nil {
+			return Problems{{Rule: "syntax", Message: err.Error()}}
+		}
+
+		var ps Problems
+		for _, r := range rules {
+			ps = append(ps, r(ts)...)
+		}
+
+		if len(ps) > 0 {
+			return ps
+		}
+
+		return nil
+	}
+}
+
+// Default returns a linter rejecting DDL, multiple statements and unbounded writes, and requiring the given scopes
+func Default(scopes ...Scope) Lint {
+	rules := []Rule{NoDDL(), SingleStatement(), BoundedWrites()}
+	for _, s := range scopes {
+		rules = append(rules, Scoped(s))
+	}
+
+	return Rules(rules...)
+}
+
+// statements splits tokens into statements, dropping empty ones
+func statements(ts []Token) [][]Token {
+	var (
+		res   [][]Token
+		start int
+	)
+
+	for i, t := range ts {
+		if t.Is(";") && t.Depth == 0 {
+			if i > start {
+				res = append(res, ts[start:i])
+			}
+			start = i + 1
+		}
+	}
+
+	if start < len(ts) {
+		res = append(res, ts[start:])
+	}
+
+	return res
+}
+
+func problem(rule, format string, args ...interface{}) Problem {
+	return Problem{Rule: rule, Message: fmt.Sprintf(format, args...)}
+}
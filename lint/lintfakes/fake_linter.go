@@ -0,0 +1,111 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package lintfakes
+
+import (
+	"sync"
+
+	"github.com/at-silva/ddapi/lint"
+)
+
+type FakeLinter struct {
+	LintStub        func(string) error
+	lintMutex       sync.RWMutex
+	lintArgsForCall []struct {
+		arg1 string
+	}
+	lintReturns struct {
+		result1 error
+	}
+	lintReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeLinter) Lint(arg1 string) error {
+	fake.lintMutex.Lock()
+	ret, specificReturn := fake.lintReturnsOnCall[len(fake.lintArgsForCall)]
+	fake.lintArgsForCall = append(fake.lintArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.LintStub
+	fakeReturns := fake.lintReturns
+	fake.recordInvocation("Lint", []interface{}{arg1})
+	fake.lintMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLinter) LintCallCount() int {
+	fake.lintMutex.RLock()
+	defer fake.lintMutex.RUnlock()
+	return len(fake.lintArgsForCall)
+}
+
+func (fake *FakeLinter) LintCalls(stub func(string) error) {
+	fake.lintMutex.Lock()
+	defer fake.lintMutex.Unlock()
+	fake.LintStub = stub
+}
+
+func (fake *FakeLinter) LintArgsForCall(i int) string {
+	fake.lintMutex.RLock()
+	defer fake.lintMutex.RUnlock()
+	argsForCall := fake.lintArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLinter) LintReturns(result1 error) {
+	fake.lintMutex.Lock()
+	defer fake.lintMutex.Unlock()
+	fake.LintStub = nil
+	fake.lintReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeLinter) LintReturnsOnCall(i int, result1 error) {
+	fake.lintMutex.Lock()
+	defer fake.lintMutex.Unlock()
+	fake.LintStub = nil
+	if fake.lintReturnsOnCall == nil {
+		fake.lintReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.lintReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeLinter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.lintMutex.RLock()
+	defer fake.lintMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeLinter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ lint.Linter = new(FakeLinter)
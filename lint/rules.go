@@ -0,0 +1,91 @@
+package lint
+
+import "strings"
+
+var ddl = map[string]bool{
+	"create":   true,
+	"alter":    true,
+	"drop":     true,
+	"truncate": true,
+	"rename":   true,
+	"grant":    true,
+	"revoke":   true,
+	"comment":  true,
+}
+
+// NoDDL rejects statements changing the schema or its permissions (create, alter, drop, truncate, rename, grant, revoke and comment)
+func NoDDL() Rule {
+	return func(ts []Token) []Problem {
+		var ps []Problem
+		for _, s := range statements(ts) {
+			v := verb(s, 0)
+			if v >= 0 && ddl[strings.ToLower(s[v].Text)] {
+				ps = append(ps, problem("no-ddl", "%s statements are not allowed", strings.ToLower(s[v].Text)))
+			}
+		}
+
+		return ps
+	}
+}
+
+// SingleStatement rejects payloads holding more than one statement, a trailing semicolon is allowed
+func SingleStatement() Rule {
+	return func(ts []Token) []Problem {
+		if n := len(statements(ts)); n > 1 {
+			return []Problem{problem("single-statement", "expected a single statement, found %d", n)}
+		}
+
+		return nil
+	}
+}
+
+// BoundedWrites rejects update and delete statements without a where clause, including the ones found in CTEs
+func BoundedWrites() Rule {
+	return func(ts []Token) []Problem {
+		var ps []Problem
+		for i, t := range ts {
+			if !(t.Is("update") || t.Is("delete")) || !startsStatement(ts, i) {
+				continue
+			}
+
+			if !hasWhere(ts, i) {
+				ps = append(ps, problem("bounded-writes", "%s without a where clause", strings.ToLower(t.Text)))
+			}
+		}
+
+		return ps
+	}
+}
+
+// verb returns the index of the first keyword of a statement, skipping leading parenthesis
+func verb(s []Token, from int) int {
+	for i := from; i < len(s); i++ {
+		if !s[i].Is("(") {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// startsStatement reports whether the token at i starts a statement (or a CTE body) rather than being part
+// of a clause like "on conflict do update" or "for update"
+func startsStatement(ts []Token, i int) bool {
+	return i == 0 || ts[i-1].Is("(") || ts[i-1].Is(")") || ts[i-1].Is(";")
+}
+
+// hasWhere reports whether the statement starting at i has a where clause at its own depth
+func hasWhere(ts []Token, i int) bool {
+	d := ts[i].Depth
+	for _, t := range ts[i+1:] {
+		if t.Depth < d || (t.Depth == d && t.Is(";")) {
+			return false
+		}
+
+		if t.Depth == d && t.Is("where") {
+			return true
+		}
+	}
+
+	return false
+}
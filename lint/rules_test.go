@@ -0,0 +1,66 @@
+package lint_test
+
+import (
+	"github.com/at-silva/ddapi/lint"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rules", func() {
+
+	Describe("NoDDL", func() {
+		It("should reject statements changing the schema", func() {
+			l := lint.Rules(lint.NoDDL())
+			Expect(l.Lint("DROP TABLE product")).Should(MatchError("no-ddl: drop statements are not allowed"))
+			Expect(l.Lint("select 1; truncate product")).Should(MatchError("no-ddl: truncate statements are not allowed"))
+			Expect(l.Lint("grant all on product to public")).Should(HaveOccurred())
+		})
+
+		It("should accept queries and DML", func() {
+			l := lint.Rules(lint.NoDDL())
+			Expect(l.Lint("select 'drop table product' as \"create\"")).Should(Succeed())
+			Expect(l.Lint("insert into product(name) values(:name)")).Should(Succeed())
+		})
+	})
+
+	Describe("SingleStatement", func() {
+		It("should reject multiple statements", func() {
+			Expect(lint.Rules(lint.SingleStatement()).Lint("select 1; select 2")).Should(MatchError("single-statement: expected a single statement, found 2"))
+		})
+
+		It("should accept a trailing semicolon and semicolons in strings", func() {
+			Expect(lint.Rules(lint.SingleStatement()).Lint("select ';' ;")).Should(Succeed())
+		})
+	})
+
+	Describe("BoundedWrites", func() {
+		It("should reject updates and deletes without a where clause", func() {
+			l := lint.Rules(lint.BoundedWrites())
+			Expect(l.Lint("delete from product")).Should(MatchError("bounded-writes: delete without a where clause"))
+			Expect(l.Lint("update product set name = (select name from x where id = 1)")).Should(MatchError("bounded-writes: update without a where clause"))
+			Expect(l.Lint("with d as (delete from product returning id) select * from d")).Should(HaveOccurred())
+		})
+
+		It("should accept bounded writes", func() {
+			l := lint.Rules(lint.BoundedWrites())
+			Expect(l.Lint("delete from product where id = :id")).Should(Succeed())
+			Expect(l.Lint("with x as (select 1) update product set name = :name where id = :id")).Should(Succeed())
+			Expect(l.Lint("insert into product(id) values(:id) on conflict (id) do update set name = :name")).Should(Succeed())
+			Expect(l.Lint("select * from product for update")).Should(Succeed())
+		})
+	})
+
+	Describe("Default", func() {
+		It("should report every problem found", func() {
+			err := lint.Default().Lint("delete from product; drop table product")
+			Expect(err).Should(MatchError("no-ddl: drop statements are not allowed; single-statement: expected a single statement, found 2; bounded-writes: delete without a where clause"))
+			Expect(err).Should(BeAssignableToTypeOf(lint.Problems{}))
+		})
+
+		It("should report statements that cannot be tokenized", func() {
+			Expect(lint.Default().Lint("select 'abc")).Should(MatchError("syntax: unterminated string"))
+		})
+	})
+
+})
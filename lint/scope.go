@@ -0,0 +1,485 @@
+package lint
+
+import "strings"
+
+// Scope a predicate every table referenced by a statement must be filtered by, e.g. tenant_id = :tenant_id,
+// where the param is one of the session claims injected by handler.ReadSession
+type Scope struct {
+	// Column the scoping column
+	Column string
+	// Claims the session claims the column may be bound to, defaults to the column name
+	Claims []string
+	// Except tables exempt from the scope, e.g. lookup tables shared by every tenant
+	Except []string
+}
+
+type (
+	// segment the tokens of a single query block: one paren group level, split by set operators
+	segment []int
+
+	tableRef struct {
+		name  string
+		alias string
+	}
+)
+
+// clause boundaries, a where or on clause ends at the first of these keywords found at its own depth
+var clauseEnd = map[string]bool{
+	"where": true, "on": true, "join": true, "inner": true, "left": true, "right": true, "full": true,
+	"cross": true, "natural": true, "group": true, "order": true, "having": true, "limit": true,
+	"offset": true, "fetch": true, "window": true, "returning": true, "for": true, "using": true,
+	"set": true, "values": true, "select": true, "from": true, "union": true, "intersect": true, "except": true,
+}
+
+// keywords starting a query block
+var subquery = map[string]bool{
+	"select": true, "with": true, "values": true, "table": true, "insert": true, "update": true, "delete": true,
+	"merge": true,
+}
+
+// keywords that cannot be a table alias
+var notAlias = map[string]bool{
+	"where": true, "on": true, "using": true, "join": true, "inner": true, "left": true, "right": true,
+	"full": true, "outer": true, "cross": true, "natural": true, "straight_join": true, "group": true,
+	"order": true, "having": true, "limit": true, "offset": true, "fetch": true, "window": true,
+	"returning": true, "for": true, "set": true, "values": true, "select": true, "union": true,
+	"intersect": true, "except": true, "default": true, "lateral": true, "tablesample": true, "as": true,
+}
+
+// Scoped requires every table referenced by a statement to be filtered by the given scope: selects,
+// updates and deletes must hold a [table.]column = :claim predicate in a where or on clause not combined with or,
+// xor or ||, inserts must set the column to the claim and updates cannot set it to anything else.
+// Each query block (subqueries, CTE bodies, set operations) is checked on its own
+func Scoped(s Scope) Rule {
+	if len(s.Claims) == 0 {
+		s.Claims = []string{s.Column}
+	}
+
+	return func(ts []Token) []Problem {
+		var ps []Problem
+		for _, st := range statements(ts) {
+			ctes := cteNames(st)
+			for _, seg := range segments(st) {
+				ps = append(ps, s.check(st, seg, ctes)...)
+			}
+		}
+
+		return ps
+	}
+}
+
+func (s Scope) check(ts []Token, seg segment, ctes map[string]bool) []Problem {
+	var (
+		ps    []Problem
+		refs  []tableRef
+		items int
+		verb  string
+	)
+
+	for k := 0; k < len(seg); k++ {
+		t := ts[seg[k]]
+		if verb == "" && (t.Is("select") || t.Is("update") || t.Is("delete") || t.Is("insert")) {
+			verb = strings.ToLower(t.Text)
+		}
+
+		switch {
+		case t.Is("into") && verb == "insert":
+			ref, next := s.tableItem(ts, seg, k+1, true)
+			if ref.name != "" && !s.except(ref.name) && !ctes[strings.ToLower(ref.name)] {
+				ps = append(ps, s.checkInsert(ts, seg, ref, next)...)
+			}
+			k = next - 1
+		case t.Is("from") || t.Is("join") || (t.Is("update") && verb == "update") || (t.Is("using") && k+1 < len(seg) && !ts[seg[k+1]].Is("(")):
+			next := k + 1
+			for {
+				ref, n := s.tableItem(ts, seg, next, false)
+				items++
+				if ref.name != "" && !s.except(ref.name) && !ctes[strings.ToLower(ref.name)] {
+					refs = append(refs, ref)
+				}
+
+				next = n
+				if t.Is("join") || next >= len(seg) || !ts[seg[next]].Is(",") {
+					break
+				}
+				next++
+			}
+			k = next - 1
+		}
+	}
+
+	for _, ref := range refs {
+		if !s.filtered(ts, seg, ref, items == 1) {
+			ps = append(ps, problem("scope", "table %s must be filtered by %s = %s", ref.name, s.Column, s.claims()))
+		}
+	}
+
+	if verb == "update" {
+		ps = append(ps, s.checkSet(ts, seg)...)
+	}
+
+	return ps
+}
+
+// tableItem reads a [schema.]table [[as] alias] item starting at seg[k], subqueries and table functions
+// are skipped and returned without a name, insert targets are followed by their column list instead
+func (s Scope) tableItem(ts []Token, seg segment, k int, insert bool) (tableRef, int) {
+	var ref tableRef
+	if k < len(seg) && ts[seg[k]].Is("lateral") {
+		k++
+	}
+
+	if k >= len(seg) || ts[seg[k]].Is("(") {
+		return ref, skipGroup(ts, seg, k)
+	}
+
+	for k < len(seg) && ts[seg[k]].Ident() {
+		ref.name = ts[seg[k]].Text
+		k++
+		if k+1 < len(seg) && ts[seg[k]].Is(".") && ts[seg[k+1]].Ident() {
+			k++
+			continue
+		}
+		break
+	}
+
+	if k < len(seg) && ts[seg[k]].Is("(") {
+		if insert {
+			return ref, k
+		}
+
+		return tableRef{}, skipGroup(ts, seg, k)
+	}
+
+	if k+1 < len(seg) && ts[seg[k]].Is("as") && ts[seg[k+1]].Ident() {
+		ref.alias = ts[seg[k+1]].Text
+		return ref, k + 2
+	}
+
+	if k < len(seg) && ts[seg[k]].Ident() && !notAlias[strings.ToLower(ts[seg[k]].Text)] {
+		ref.alias = ts[seg[k]].Text
+		k++
+	}
+
+	return ref, k
+}
+
+// filtered reports whether a where or on clause of the segment holds the scope predicate for the given table
+func (s Scope) filtered(ts []Token, seg segment, ref tableRef, single bool) bool {
+	for k := 0; k < len(seg); k++ {
+		if !ts[seg[k]].Is("where") && !ts[seg[k]].Is("on") {
+			continue
+		}
+
+		end := k + 1
+		for end < len(seg) && !(ts[seg[end]].Kind == Word && clauseEnd[strings.ToLower(ts[seg[end]].Text)]) {
+			end++
+		}
+
+		if s.predicate(ts, seg[k+1:end], ref, single) {
+			return true
+		}
+		k = end - 1
+	}
+
+	return false
+}
+
+// predicate reports whether the clause holds the scope predicate, and is not combined with a disjunction:
+// or, xor or the MySQL || operator (which other dialects use for concatenation, rejecting it there is harmless)
+func (s Scope) predicate(ts []Token, clause segment, ref tableRef, single bool) bool {
+	for _, k := range clause {
+		if ts[k].Is("or") || ts[k].Is("xor") || ts[k].Is("||") {
+			return false
+		}
+	}
+
+	for i := range clause {
+		col, q, n := column(ts, clause, i)
+		if col == "" || !strings.EqualFold(col, s.Column) || !s.qualified(q, ref, single) {
+			continue
+		}
+
+		if i > 0 && ts[clause[i-1]].Is("not") {
+			continue
+		}
+
+		if i+n+1 < len(clause) && ts[clause[i+n]].Is("=") && s.claim(ts[clause[i+n+1]]) {
+			return true
+		}
+
+		if i >= 2 && ts[clause[i-1]].Is("=") && s.claim(ts[clause[i-2]]) && (i < 3 || !ts[clause[i-3]].Is("not")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkInsert requires the insert to set the scope column to the claim, for every row of a values list
+// or for the select list of an insert ... select
+func (s Scope) checkInsert(ts []Token, seg segment, ref tableRef, k int) []Problem {
+	bad := []Problem{problem("scope", "insert into %s must set %s to %s", ref.name, s.Column, s.claims())}
+	if k >= len(seg) || !ts[seg[k]].Is("(") {
+		return bad
+	}
+
+	end := skipGroup(ts, seg, k)
+	pos := -1
+	for i, c := range split(ts, seg[k], seg[end-1]) {
+		if len(c) == 1 && strings.EqualFold(ts[c[0]].Text, s.Column) {
+			pos = i
+		}
+	}
+
+	if pos < 0 || end >= len(seg) {
+		return bad
+	}
+
+	switch {
+	case ts[seg[end]].Is("values"):
+		rows := 0
+		for k = end + 1; k < len(seg) && ts[seg[k]].Is("("); {
+			next := skipGroup(ts, seg, k)
+			vs := split(ts, seg[k], seg[next-1])
+			if pos >= len(vs) || len(vs[pos]) != 1 || !s.claim(ts[vs[pos][0]]) {
+				return bad
+			}
+
+			rows++
+			if next >= len(seg) || !ts[seg[next]].Is(",") {
+				break
+			}
+			k = next + 1
+		}
+
+		if rows == 0 {
+			return bad
+		}
+	case ts[seg[end]].Is("select"):
+		from := end + 1
+		for from < len(seg) && !ts[seg[from]].Is("from") {
+			from++
+		}
+
+		vs := splitList(ts, seg[end+1:from])
+		if pos >= len(vs) || len(vs[pos]) != 1 || !s.claim(ts[vs[pos][0]]) {
+			return bad
+		}
+	default:
+		return bad
+	}
+
+	return nil
+}
+
+// checkSet rejects updates setting the scope column to anything but the claim
+func (s Scope) checkSet(ts []Token, seg segment) []Problem {
+	set := -1
+	for k := range seg {
+		if ts[seg[k]].Is("set") {
+			set = k
+			break
+		}
+	}
+
+	if set < 0 {
+		return nil
+	}
+
+	for k := set + 1; k < len(seg); k++ {
+		t := ts[seg[k]]
+		if t.Is("where") || t.Is("from") || t.Is("returning") {
+			break
+		}
+
+		col, _, n := column(ts, seg[k:], 0)
+		if col == "" || !strings.EqualFold(col, s.Column) || k+n >= len(seg) || !ts[seg[k+n]].Is("=") {
+			continue
+		}
+
+		v := k + n + 1
+		if v >= len(seg) || !s.claim(ts[seg[v]]) || (v+1 < len(seg) && !ts[seg[v+1]].Is(",") && !clauseEnd[strings.ToLower(ts[seg[v+1]].Text)]) {
+			return []Problem{problem("scope", "update cannot set %s to anything but %s", s.Column, s.claims())}
+		}
+	}
+
+	return nil
+}
+
+// column reads a [qualifier.]column reference at c[i], returning the column, its qualifier and its length in tokens
+func column(ts []Token, c segment, i int) (string, string, int) {
+	if !ts[c[i]].Ident() {
+		return "", "", 0
+	}
+
+	if i > 0 && ts[c[i-1]].Is(".") {
+		return "", "", 0
+	}
+
+	if i+2 < len(c) && ts[c[i+1]].Is(".") && ts[c[i+2]].Ident() {
+		return ts[c[i+2]].Text, ts[c[i]].Text, 3
+	}
+
+	return ts[c[i]].Text, "", 1
+}
+
+func (s Scope) qualified(q string, ref tableRef, single bool) bool {
+	if q == "" {
+		return single
+	}
+
+	if ref.alias != "" {
+		return strings.EqualFold(q, ref.alias)
+	}
+
+	return strings.EqualFold(q, ref.name)
+}
+
+func (s Scope) claim(t Token) bool {
+	if t.Kind != Param {
+		return false
+	}
+
+	for _, c := range s.Claims {
+		if t.Text == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s Scope) claims() string {
+	return ":" + strings.Join(s.Claims, " or :")
+}
+
+func (s Scope) except(table string) bool {
+	for _, e := range s.Except {
+		if strings.EqualFold(e, table) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// segments splits a statement into its query blocks, every paren group starts a new set of blocks and
+// set operators (union, intersect, except) split the blocks of a group. Function arguments are not query blocks,
+// e.g. extract(year from d), they are dropped while the subqueries they hold are kept
+func segments(ts []Token) []segment {
+	var (
+		res   []segment
+		stack []segment
+		args  []bool
+		cur   segment
+	)
+
+	for i, t := range ts {
+		switch {
+		case t.Is("("):
+			cur = append(cur, i)
+			stack = append(stack, cur)
+			args = append(args, funcArgs(ts, i))
+			cur = nil
+		case t.Is(")"):
+			if len(cur) > 0 && !args[len(args)-1] {
+				res = append(res, cur)
+			}
+			cur = append(stack[len(stack)-1], i)
+			stack = stack[:len(stack)-1]
+			args = args[:len(args)-1]
+		case t.Is("union") || t.Is("intersect") || t.Is("except"):
+			res = append(res, cur)
+			cur = nil
+		default:
+			cur = append(cur, i)
+		}
+	}
+
+	if len(cur) > 0 {
+		res = append(res, cur)
+	}
+
+	return res
+}
+
+// funcArgs reports whether the paren group opened at ts[i] holds the arguments of a function call: it follows
+// a name which is not a keyword, and doesn't start a subquery as in exists(select ...) or array(select ...)
+func funcArgs(ts []Token, i int) bool {
+	if i == 0 || i+1 >= len(ts) || !ts[i-1].Ident() {
+		return false
+	}
+
+	if prev := strings.ToLower(ts[i-1].Text); ts[i-1].Kind == Word && (clauseEnd[prev] || notAlias[prev]) {
+		return false
+	}
+
+	return !(ts[i+1].Kind == Word && subquery[strings.ToLower(ts[i+1].Text)])
+}
+
+// cteNames returns the (lower cased) names of the CTEs defined by a statement
+func cteNames(ts []Token) map[string]bool {
+	res := map[string]bool{}
+	for i := 1; i+2 < len(ts); i++ {
+		if !ts[i].Ident() || !(ts[i-1].Is("with") || ts[i-1].Is(",") || ts[i-1].Is("recursive")) {
+			continue
+		}
+
+		j := i + 1
+		if ts[j].Is("(") {
+			for j < len(ts) && !(ts[j].Is(")") && ts[j].Depth == ts[i].Depth) {
+				j++
+			}
+			j++
+		}
+
+		if j+1 < len(ts) && ts[j].Is("as") && ts[j+1].Is("(") {
+			res[strings.ToLower(ts[i].Text)] = true
+		}
+	}
+
+	return res
+}
+
+// skipGroup returns the index following the paren group starting at seg[k]
+func skipGroup(ts []Token, seg segment, k int) int {
+	if k >= len(seg) || !ts[seg[k]].Is("(") {
+		return k
+	}
+
+	for k++; k < len(seg) && !ts[seg[k]].Is(")"); k++ {
+	}
+
+	return k + 1
+}
+
+// split splits the tokens of the paren group between open and close by their top level commas
+func split(ts []Token, open, close int) [][]int {
+	var list []int
+	for i := open + 1; i < close; i++ {
+		if ts[i].Depth == ts[open].Depth+1 {
+			list = append(list, i)
+		}
+	}
+
+	return splitList(ts, list)
+}
+
+func splitList(ts []Token, list []int) [][]int {
+	var (
+		res [][]int
+		cur []int
+	)
+
+	for _, i := range list {
+		if ts[i].Is(",") {
+			res = append(res, cur)
+			cur = nil
+			continue
+		}
+		cur = append(cur, i)
+	}
+
+	return append(res, cur)
+}
@@ -0,0 +1,84 @@
+package lint_test
+
+import (
+	"github.com/at-silva/ddapi/lint"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scoped", func() {
+
+	var l lint.Lint
+
+	BeforeEach(func() {
+		l = lint.Rules(lint.Scoped(lint.Scope{Column: "tenant_id", Claims: []string{"tenant_id", "tid"}, Except: []string{"country"}}))
+	})
+
+	It("should accept statements filtered by the scope", func() {
+		for _, q := range []string{
+			"select * from product where tenant_id = :tenant_id and name like :name",
+			"select * from product where :tid = tenant_id",
+			"select * from app.product p where p.tenant_id = :tenant_id",
+			"select * from product p join category c on c.id = p.category_id and c.tenant_id = :tenant_id where p.tenant_id = :tenant_id",
+			"select * from product p, category c where p.tenant_id = :tenant_id and c.tenant_id = :tenant_id and c.id = p.category_id",
+			"select * from product where tenant_id = :tenant_id and (name = :name or code = :code)",
+			"select * from product where tenant_id = :tenant_id and id in (select product_id from stock where tenant_id = :tenant_id)",
+			"with p as (select * from product where tenant_id = :tenant_id) select * from p",
+			"select id from product where tenant_id = :tenant_id union select id from service where tenant_id = :tenant_id",
+			"select * from country",
+			"select 1",
+			"update product set name = :name where id = :id and tenant_id = :tenant_id",
+			"update product set tenant_id = :tenant_id, name = :name where id = :id and tenant_id = :tenant_id",
+			"delete from product where tenant_id = :tenant_id and id = :id",
+			"insert into product(name, tenant_id) values(:name, :tenant_id), (:name2, :tid)",
+			"insert into product(name, tenant_id) select name, :tenant_id from template where tenant_id = :tenant_id",
+			"select * from product as p where p.tenant_id = :tenant_id",
+			"select * from product p join country c on c.id = p.country_id where p.tenant_id = :tenant_id",
+			"select * from product where tenant_id = :tenant_id and name = 'a or b'",
+			"select extract(year from created_at) from product where tenant_id = :tenant_id",
+			"select substring(name from 1 for 3), trim(both ' ' from code) from product where tenant_id = :tenant_id",
+			"select coalesce((select max(price) from price where tenant_id = :tenant_id), 0) from product where tenant_id = :tenant_id",
+			"select * from product where tenant_id = :tenant_id and exists (select 1 from stock s where s.product_id = product.id and s.tenant_id = :tenant_id)",
+			"with recursive t(n) as (select 1 union all select n + 1 from t where n < 10) select * from t",
+			"update product p set name = :name from category c where c.id = p.category_id and c.tenant_id = :tenant_id and p.tenant_id = :tenant_id",
+			"delete from product p using category c where c.id = p.category_id and c.tenant_id = :tenant_id and p.tenant_id = :tenant_id",
+		} {
+			Expect(l.Lint(q)).Should(Succeed(), q)
+		}
+	})
+
+	It("should reject statements not filtered by the scope", func() {
+		for q, msg := range map[string]string{
+			"select * from product":                                                                                      "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product where tenant_id = :id":                                                                "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product where tenant_id = 1":                                                                  "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product where tenant_id = :tenant_id or 1 = 1":                                                "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product where not tenant_id = :tenant_id":                                                     "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product p join category c on c.id = p.category_id where p.tenant_id = :tenant_id":             "scope: table category must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product p join category c on c.id = p.category_id where tenant_id = :tenant_id":               "scope: table product must be filtered by tenant_id = :tenant_id or :tid; scope: table category must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product where id in (select id from product where tenant_id = :tenant_id)":                    "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"select id from product where tenant_id = :tenant_id union select id from service":                           "scope: table service must be filtered by tenant_id = :tenant_id or :tid",
+			"update product set tenant_id = :other where tenant_id = :tenant_id":                                         "scope: update cannot set tenant_id to anything but :tenant_id or :tid",
+			"delete from product where id = :id":                                                                         "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"insert into product(name) values(:name)":                                                                    "scope: insert into product must set tenant_id to :tenant_id or :tid",
+			"insert into product(name, tenant_id) values(:name, :tenant_id), (:name, 1)":                                 "scope: insert into product must set tenant_id to :tenant_id or :tid",
+			"insert into product values(:name, :tenant_id)":                                                              "scope: insert into product must set tenant_id to :tenant_id or :tid",
+			"select * from product where tenant_id = :tenant_id || 1=1":                                                  "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product where tenant_id = :tenant_id xor 1":                                                   "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product where tenant_id != :tenant_id":                                                        "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product p where c.tenant_id = :tenant_id":                                                     "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product where tenant_id = :tenant_id and price > coalesce((select max(price) from price), 0)": "scope: table price must be filtered by tenant_id = :tenant_id or :tid",
+			"select array(select id from stock) from product where tenant_id = :tenant_id":                               "scope: table stock must be filtered by tenant_id = :tenant_id or :tid",
+			"select * from product where exists (select 1 from stock)":                                                   "scope: table stock must be filtered by tenant_id = :tenant_id or :tid; scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"with p as (select * from product) select * from p":                                                          "scope: table product must be filtered by tenant_id = :tenant_id or :tid",
+			"update product p set name = :name from category c where c.id = p.category_id and p.tenant_id = :tenant_id":  "scope: table category must be filtered by tenant_id = :tenant_id or :tid",
+			"delete from product p using category c where c.id = p.category_id and p.tenant_id = :tenant_id":             "scope: table category must be filtered by tenant_id = :tenant_id or :tid",
+			"update product set tenant_id = :tenant_id + 1 where tenant_id = :tenant_id":                                 "scope: update cannot set tenant_id to anything but :tenant_id or :tid",
+			"insert into product(name, tenant_id) select name, tenant_id from template where tenant_id = :tenant_id":     "scope: insert into product must set tenant_id to :tenant_id or :tid",
+		} {
+			Expect(l.Lint(q)).Should(MatchError(msg), q)
+		}
+	})
+
+})
@@ -0,0 +1,194 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Token kinds
+const (
+	Word TokenKind = iota
+	QuotedIdent
+	String
+	Number
+	Param
+	Punct
+)
+
+type (
+	// TokenKind the kind of a SQL token
+	TokenKind int
+
+	// Token a SQL token, comments and whitespace are dropped
+	Token struct {
+		Kind TokenKind
+		// Text the token as found in the statement, quoted identifiers and strings are unquoted
+		Text string
+		// Depth the parenthesis nesting level of the token
+		Depth int
+	}
+)
+
+// Tokenize splits a statement into tokens, it understands enough SQL to tell keywords and identifiers
+// from strings, comments, named parameters and punctuation across the mainstream dialects
+func Tokenize(q string) ([]Token, error) {
+	var (
+		ts    []Token
+		depth int
+	)
+
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case isSpace(c):
+			i++
+		case strings.HasPrefix(q[i:], "--"):
+			end := strings.IndexByte(q[i:], '\n')
+			if end < 0 {
+				return ts, nil
+			}
+			i += end + 1
+		case strings.HasPrefix(q[i:], "/*"):
+			end := strings.Index(q[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '\'':
+			s, n, err := quoted(q[i:], '\'')
+			if err != nil {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			ts = append(ts, Token{Kind: String, Text: s, Depth: depth})
+			i += n
+		case c == '"' || c == '`':
+			s, n, err := quoted(q[i:], c)
+			if err != nil {
+				return nil, fmt.Errorf("unterminated identifier")
+			}
+			ts = append(ts, Token{Kind: QuotedIdent, Text: s, Depth: depth})
+			i += n
+		case c == '[':
+			end := strings.IndexByte(q[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated identifier")
+			}
+			ts = append(ts, Token{Kind: QuotedIdent, Text: q[i+1 : i+end], Depth: depth})
+			i += end + 1
+		case c == '$' && dollarTag(q[i:]) != "":
+			tag := dollarTag(q[i:])
+			end := strings.Index(q[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			ts = append(ts, Token{Kind: String, Text: q[i+len(tag) : i+len(tag)+end], Depth: depth})
+			i += 2*len(tag) + end
+		case (c == ':' || c == '@' || c == '$') && i+1 < len(q) && isWord(q[i+1]) && !strings.HasPrefix(q[i:], "::") && (i == 0 || q[i-1] != ':'):
+			n := 1 + wordLen(q[i+1:])
+			ts = append(ts, Token{Kind: Param, Text: q[i+1 : i+n], Depth: depth})
+			i += n
+		case c == '?':
+			ts = append(ts, Token{Kind: Param, Text: "?", Depth: depth})
+			i++
+		case isDigit(c):
+			n := 0
+			for i+n < len(q) && (isWord(q[i+n]) || q[i+n] == '.') {
+				n++
+			}
+			ts = append(ts, Token{Kind: Number, Text: q[i : i+n], Depth: depth})
+			i += n
+		case isWord(c):
+			n := wordLen(q[i:])
+			ts = append(ts, Token{Kind: Word, Text: q[i : i+n], Depth: depth})
+			i += n
+		case c == '(':
+			ts = append(ts, Token{Kind: Punct, Text: "(", Depth: depth})
+			depth++
+			i++
+		case c == ')':
+			if depth == 0 {
+				return nil, fmt.Errorf("unbalanced parenthesis")
+			}
+			depth--
+			ts = append(ts, Token{Kind: Punct, Text: ")", Depth: depth})
+			i++
+		default:
+			n := 1
+			for _, op := range []string{"::", "<=", ">=", "<>", "!=", "||", "=>", "->>", "->"} {
+				if strings.HasPrefix(q[i:], op) {
+					n = len(op)
+					break
+				}
+			}
+			ts = append(ts, Token{Kind: Punct, Text: q[i : i+n], Depth: depth})
+			i += n
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parenthesis")
+	}
+
+	return ts, nil
+}
+
+// Is reports whether the token is the given (case insensitive) keyword or punctuation
+func (t Token) Is(s string) bool {
+	return (t.Kind == Word || t.Kind == Punct) && strings.EqualFold(t.Text, s)
+}
+
+// Ident reports whether the token can be an identifier
+func (t Token) Ident() bool {
+	return t.Kind == Word || t.Kind == QuotedIdent
+}
+
+// quoted returns the unquoted content of a string or identifier starting at s[0] along with its length,
+// the quote is escaped by doubling it. Backslash escapes are not supported on purpose, a dialect that doesn't
+// honor them would otherwise see more SQL than the linter does
+func quoted(s string, q byte) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == q && i+1 < len(s) && s[i+1] == q:
+			b.WriteByte(q)
+			i++
+		case s[i] == q:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated")
+}
+
+// dollarTag returns the $tag$ opening a dollar quoted (postgres) string, if any
+func dollarTag(s string) string {
+	n := 1 + wordLen(s[1:])
+	if n < len(s) && s[n] == '$' && (n == 1 || !isDigit(s[1])) {
+		return s[:n+1]
+	}
+
+	return ""
+}
+
+func wordLen(s string) int {
+	n := 0
+	for n < len(s) && isWord(s[n]) {
+		n++
+	}
+
+	return n
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWord(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
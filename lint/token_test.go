@@ -0,0 +1,55 @@
+package lint_test
+
+import (
+	"github.com/at-silva/ddapi/lint"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tokenize", func() {
+
+	texts := func(ts []lint.Token) []string {
+		res := make([]string, len(ts))
+		for i, t := range ts {
+			res[i] = t.Text
+		}
+		return res
+	}
+
+	It("should split a statement into tokens, dropping comments", func() {
+		ts, err := lint.Tokenize("select p.name, count(*) -- count\nfrom product p /* all */ where p.id >= :id::int")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(texts(ts)).Should(Equal([]string{"select", "p", ".", "name", ",", "count", "(", "*", ")", "from", "product", "p", "where", "p", ".", "id", ">=", "id", "::", "int"}))
+		Expect(ts[17].Kind).Should(Equal(lint.Param))
+		Expect(ts[6].Depth).Should(Equal(0))
+		Expect(ts[7].Depth).Should(Equal(1))
+		Expect(ts[8].Depth).Should(Equal(0))
+	})
+
+	It("should not mistake strings and quoted identifiers for keywords", func() {
+		ts, err := lint.Tokenize(`select 'drop table; it''s' from "order" where [select] = $$ ; $$ and name = $tag$x$tag$ and id = $1`)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(ts[1]).Should(Equal(lint.Token{Kind: lint.String, Text: "drop table; it's"}))
+		Expect(ts[3]).Should(Equal(lint.Token{Kind: lint.QuotedIdent, Text: "order"}))
+		Expect(ts[5]).Should(Equal(lint.Token{Kind: lint.QuotedIdent, Text: "select"}))
+		Expect(ts[7]).Should(Equal(lint.Token{Kind: lint.String, Text: " ; "}))
+		Expect(ts[11]).Should(Equal(lint.Token{Kind: lint.String, Text: "x"}))
+		Expect(ts[15]).Should(Equal(lint.Token{Kind: lint.Param, Text: "1"}))
+	})
+
+	It("should fail on unterminated strings, comments and unbalanced parenthesis", func() {
+		_, err := lint.Tokenize("select 'abc")
+		Expect(err).Should(MatchError("unterminated string"))
+
+		_, err = lint.Tokenize("select 1 /* abc")
+		Expect(err).Should(MatchError("unterminated comment"))
+
+		_, err = lint.Tokenize("select (1")
+		Expect(err).Should(MatchError("unbalanced parenthesis"))
+
+		_, err = lint.Tokenize("select 1)")
+		Expect(err).Should(MatchError("unbalanced parenthesis"))
+	})
+
+})
@@ -0,0 +1,169 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/lint"
+)
+
+type (
+	// SignOptions controls how statements are signed
+	SignOptions struct {
+		Signer check.Signer
+		// Header when set, signatures are stamped with it (see check.Stamp), bundled statements default its id to their own
+		Header *check.Header
+		// Cipher when set, statements are encrypted after being signed
+		Cipher Cipher
+		// Legacy when set, the sql and params schema are signed separately instead of as an envelope
+		Legacy bool
+		// Linter when set, statements are linted and rejected before being signed
+		Linter lint.Linter
+	}
+
+	// Signed a signed statement, ready to be sent by the frontend along with its params
+	Signed struct {
+		Statement             string          `json:"statement,omitempty"`
+		EncryptedStatement    string          `json:"encryptedStatement,omitempty"`
+		StatementSignature    string          `json:"statementSignature,omitempty"`
+		SQL                   string          `json:"sql,omitempty"`
+		SQLSignature          string          `json:"sqlSignature,omitempty"`
+		ParamsSchema          json.RawMessage `json:"paramsSchema,omitempty"`
+		ParamsSchemaSignature string          `json:"paramsSchemaSignature,omitempty"`
+	}
+)
+
+// Sign encodes and signs a statement, the signature covers its encoded (plaintext) form
+func Sign(s Statement, o SignOptions) (Signed, error) {
+	if o.Signer == nil {
+		return Signed{}, fmt.Errorf("could not sign statement: missing signer")
+	}
+
+	if o.Linter != nil {
+		err := o.Linter.Lint(s.SQL)
+		if err != nil {
+			return Signed{}, fmt.Errorf("could not lint statement: %w", err)
+		}
+	}
+
+	ps, err := compact(s.ParamsSchema)
+	if err != nil {
+		return Signed{}, err
+	}
+	s.ParamsSchema = ps
+
+	if o.Legacy {
+		return signLegacy(s, o)
+	}
+
+	e, err := Encode(s)
+	if err != nil {
+		return Signed{}, err
+	}
+
+	sig, err := sign([]byte(e), o)
+	if err != nil {
+		return Signed{}, err
+	}
+
+	res := Signed{Statement: e, StatementSignature: sig}
+	if o.Cipher == nil {
+		return res, nil
+	}
+
+	res.EncryptedStatement, err = o.Cipher.Encrypt([]byte(e))
+	if err != nil {
+		return Signed{}, fmt.Errorf("could not encrypt statement: %w", err)
+	}
+	res.Statement = ""
+
+	return res, nil
+}
+
+// Bundle signs every statement in the registry, statements are stamped with their own id unless the header sets one
+func (m Map) Bundle(o SignOptions) (map[string]Signed, error) {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	res := make(map[string]Signed, len(m))
+	for _, id := range ids {
+		so := o
+		if o.Header != nil && o.Header.ID == "" {
+			h := *o.Header
+			h.ID = id
+			so.Header = &h
+		}
+
+		s, err := Sign(m[id], so)
+		if err != nil {
+			return nil, fmt.Errorf("could not bundle statement %s: %w", id, err)
+		}
+		res[id] = s
+	}
+
+	return res, nil
+}
+
+func signLegacy(s Statement, o SignOptions) (Signed, error) {
+	if o.Cipher != nil {
+		return Signed{}, fmt.Errorf("could not sign statement: legacy signatures cannot be encrypted")
+	}
+
+	if s.SQL == "" {
+		return Signed{}, fmt.Errorf("statement sql cannot be empty")
+	}
+
+	ss, err := sign([]byte(s.SQL), o)
+	if err != nil {
+		return Signed{}, err
+	}
+
+	ps, err := sign(s.ParamsSchema, o)
+	if err != nil {
+		return Signed{}, err
+	}
+
+	return Signed{SQL: s.SQL, SQLSignature: ss, ParamsSchema: s.ParamsSchema, ParamsSchemaSignature: ps}, nil
+}
+
+// compact strips the insignificant whitespace of a params schema, as encoding/json does when writing it, so the
+// signature covers the bytes the frontend sends back
+func compact(ps json.RawMessage) (json.RawMessage, error) {
+	if len(ps) == 0 {
+		return ps, nil
+	}
+
+	var b bytes.Buffer
+	err := json.Compact(&b, ps)
+	if err != nil {
+		return nil, fmt.Errorf("could not compact params schema: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+func sign(p []byte, o SignOptions) (string, error) {
+	var (
+		s   []byte
+		err error
+	)
+
+	if o.Header != nil {
+		s, err = check.Stamp(*o.Header, p, o.Signer)
+	} else {
+		s, err = o.Signer.Sign(p)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("could not sign statement: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(s), nil
+}
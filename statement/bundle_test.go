@@ -0,0 +1,162 @@
+package statement_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/at-silva/ddapi/check"
+	"github.com/at-silva/ddapi/check/checkfakes"
+	"github.com/at-silva/ddapi/lint"
+	"github.com/at-silva/ddapi/statement"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bundle", func() {
+
+	var (
+		secret []byte
+		s      statement.Statement
+		o      statement.SignOptions
+		sig    func(s string) []byte
+	)
+
+	BeforeEach(func() {
+		secret = []byte("secret")
+		s = statement.Statement{
+			Kind:         statement.Exec,
+			SQL:          "insert into product(name) values(:name)",
+			ParamsSchema: json.RawMessage(`{"type":"object"}`),
+		}
+		o = statement.SignOptions{Signer: check.Sha256HMACSigner(secret)}
+		sig = func(s string) []byte {
+			b, err := base64.StdEncoding.DecodeString(s)
+			Expect(err).ShouldNot(HaveOccurred())
+			return b
+		}
+	})
+
+	Describe("Sign", func() {
+		It("should sign the encoded statement", func() {
+			res, err := statement.Sign(s, o)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			d, err := statement.Decode(res.Statement)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(d).Should(Equal(s))
+			Expect(check.Sha256HMAC(secret).Check([]byte(res.Statement), sig(res.StatementSignature))).Should(Succeed())
+		})
+
+		It("should stamp the signature when a header is given", func() {
+			o.Header = &check.Header{KeyID: "k1", Version: 2}
+			res, err := statement.Sign(s, o)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			h, _, _, err := check.Unstamp([]byte(res.Statement), sig(res.StatementSignature))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(h).Should(Equal(check.Header{KeyID: "k1", Version: 2}))
+			Expect(check.Expiring(check.Sha256HMAC(secret), check.Policy{}).Check([]byte(res.Statement), sig(res.StatementSignature))).Should(Succeed())
+		})
+
+		It("should encrypt the signed statement when a cipher is given", func() {
+			o.Cipher, _ = statement.AESGCM([]byte("0123456789abcdef"))
+			res, err := statement.Sign(s, o)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.Statement).Should(BeEmpty())
+
+			p, err := o.Cipher.Decrypt(res.EncryptedStatement)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(check.Sha256HMAC(secret).Check(p, sig(res.StatementSignature))).Should(Succeed())
+		})
+
+		It("should sign the sql and params schema separately in legacy mode", func() {
+			o.Legacy = true
+			res, err := statement.Sign(s, o)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res.Statement).Should(BeEmpty())
+			Expect(res.SQL).Should(Equal(s.SQL))
+			Expect(res.ParamsSchema).Should(Equal(s.ParamsSchema))
+			Expect(check.Sha256HMAC(secret).Check([]byte(res.SQL), sig(res.SQLSignature))).Should(Succeed())
+			Expect(check.Sha256HMAC(secret).Check(res.ParamsSchema, sig(res.ParamsSchemaSignature))).Should(Succeed())
+		})
+
+		It("should sign the compacted params schema", func() {
+			s.ParamsSchema = json.RawMessage("{\n  \"type\": \"object\"\n}")
+			for _, legacy := range []bool{false, true} {
+				o.Legacy = legacy
+				res, err := statement.Sign(s, o)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				b, err := json.Marshal(res)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				var sent statement.Signed
+				Expect(json.Unmarshal(b, &sent)).Should(Succeed())
+
+				if legacy {
+					Expect(sent.ParamsSchema).Should(Equal(json.RawMessage(`{"type":"object"}`)))
+					Expect(check.Sha256HMAC(secret).Check(sent.ParamsSchema, sig(sent.ParamsSchemaSignature))).Should(Succeed())
+					continue
+				}
+
+				d, err := statement.Decode(sent.Statement)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(d.ParamsSchema).Should(Equal(json.RawMessage(`{"type":"object"}`)))
+				Expect(check.Sha256HMAC(secret).Check([]byte(sent.Statement), sig(sent.StatementSignature))).Should(Succeed())
+			}
+		})
+
+		It("should fail if the params schema is invalid", func() {
+			s.ParamsSchema = json.RawMessage(`{"type":`)
+			_, err := statement.Sign(s, o)
+			Expect(err).Should(MatchError("could not compact params schema: unexpected end of JSON input"))
+		})
+
+		It("should fail if the signer fails", func() {
+			fakeSigner := new(checkfakes.FakeSigner)
+			fakeSigner.SignReturns(nil, errors.New("hsm offline"))
+			o.Signer = fakeSigner
+
+			_, err := statement.Sign(s, o)
+			Expect(err).Should(MatchError("could not sign statement: hsm offline"))
+		})
+
+		It("should refuse to sign statements rejected by the linter", func() {
+			o.Linter = lint.Default(lint.Scope{Column: "tenant_id"})
+			_, err := statement.Sign(s, o)
+			Expect(err).Should(MatchError("could not lint statement: scope: insert into product must set tenant_id to :tenant_id"))
+		})
+
+		It("should fail if there is no signer", func() {
+			_, err := statement.Sign(s, statement.SignOptions{})
+			Expect(err).Should(MatchError("could not sign statement: missing signer"))
+		})
+	})
+
+	Describe("Map.Bundle", func() {
+		It("should sign every statement, stamping each one with its id", func() {
+			m := statement.Map{"products/insert": s, "products/list": {Kind: statement.Query, SQL: "select * from product", ParamsSchema: json.RawMessage(`{}`)}}
+			o.Header = &check.Header{KeyID: "k1"}
+
+			res, err := m.Bundle(o)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(res).Should(HaveLen(2))
+
+			for id, signed := range res {
+				h, _, _, err := check.Unstamp([]byte(signed.Statement), sig(signed.StatementSignature))
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(h.ID).Should(Equal(id))
+				Expect(h.KeyID).Should(Equal("k1"))
+			}
+			Expect(o.Header.ID).Should(BeEmpty())
+		})
+
+		It("should report the statement that could not be signed", func() {
+			_, err := statement.Map{"broken": {Kind: statement.Exec}}.Bundle(statement.SignOptions{Signer: check.Sha256HMACSigner(secret), Legacy: true})
+			Expect(err).Should(MatchError("could not bundle statement broken: statement sql cannot be empty"))
+		})
+	})
+
+})
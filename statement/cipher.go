@@ -0,0 +1,69 @@
+package statement
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+type (
+	// Cipher represents a statement cipher, used to keep statements opaque to the frontend
+	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Cipher
+	Cipher interface {
+		Encrypt(plaintext []byte) (string, error)
+		Decrypt(ciphertext string) ([]byte, error)
+	}
+
+	aesGCM struct {
+		aead cipher.AEAD
+	}
+)
+
+// AESGCM returns an AES-GCM statement cipher, the key must be 16, 24 or 32 bytes long.
+// Ciphertexts are base64url encoded and carry their own random nonce
+func AESGCM(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %w", err)
+	}
+
+	return aesGCM{aead}, nil
+}
+
+// Encrypt encrypts the given plaintext
+func (c aesGCM) Encrypt(p []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(p)+c.aead.Overhead())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return "", fmt.Errorf("could not generate nonce: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, p, nil)), nil
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt
+func (c aesGCM) Decrypt(e string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode ciphertext: %w", err)
+	}
+
+	if len(b) < c.aead.NonceSize() {
+		return nil, fmt.Errorf("could not decrypt ciphertext: ciphertext too short")
+	}
+
+	p, err := c.aead.Open(nil, b[:c.aead.NonceSize()], b[c.aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt ciphertext: %w", err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,84 @@
+package statement_test
+
+import (
+	"encoding/base64"
+
+	"github.com/at-silva/ddapi/statement"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cipher", func() {
+
+	Describe("AESGCM", func() {
+
+		var (
+			c   statement.Cipher
+			p   []byte
+			err error
+		)
+
+		BeforeEach(func() {
+			c, err = statement.AESGCM([]byte("0123456789abcdef0123456789abcdef"))
+			Expect(err).ShouldNot(HaveOccurred())
+			p = []byte("insert into product(name) values(:name)")
+		})
+
+		It("should decrypt an encrypted plaintext", func() {
+			e, err := c.Encrypt(p)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(e).ShouldNot(ContainSubstring("product"))
+
+			d, err := c.Decrypt(e)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(d).Should(Equal(p))
+		})
+
+		It("should use a different nonce for every encryption", func() {
+			e1, err := c.Encrypt(p)
+			Expect(err).ShouldNot(HaveOccurred())
+			e2, err := c.Encrypt(p)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(e1).ShouldNot(Equal(e2))
+		})
+
+		It("should fail if the key size is invalid", func() {
+			_, err := statement.AESGCM([]byte("short key"))
+			Expect(err).Should(MatchError(ContainSubstring("could not create cipher")))
+		})
+
+		It("should fail if the ciphertext was encrypted with another key", func() {
+			o, err := statement.AESGCM([]byte("fedcba9876543210fedcba9876543210"))
+			Expect(err).ShouldNot(HaveOccurred())
+			e, err := o.Encrypt(p)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, err = c.Decrypt(e)
+			Expect(err).Should(MatchError(ContainSubstring("could not decrypt ciphertext")))
+		})
+
+		It("should fail if the ciphertext was tampered with", func() {
+			e, err := c.Encrypt(p)
+			Expect(err).ShouldNot(HaveOccurred())
+			b, err := base64.RawURLEncoding.DecodeString(e)
+			Expect(err).ShouldNot(HaveOccurred())
+			b[len(b)-1] ^= 1
+
+			_, err = c.Decrypt(base64.RawURLEncoding.EncodeToString(b))
+			Expect(err).Should(MatchError(ContainSubstring("could not decrypt ciphertext")))
+		})
+
+		It("should fail if the ciphertext is too short", func() {
+			_, err = c.Decrypt("AAAA")
+			Expect(err).Should(MatchError("could not decrypt ciphertext: ciphertext too short"))
+		})
+
+		It("should fail if the ciphertext is not base64url encoded", func() {
+			_, err = c.Decrypt("not base64!")
+			Expect(err).Should(MatchError(ContainSubstring("could not decode ciphertext")))
+		})
+
+	})
+
+})
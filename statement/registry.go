@@ -0,0 +1,198 @@
+package statement
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/at-silva/ddapi/lint"
+)
+
+// ErrNotFound returned by registries when a statement id is not registered
+var ErrNotFound = errors.New("statement not found")
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+type (
+	// Registry resolves statements by id, allowing clients to reference statements instead of shipping them
+	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Registry
+	Registry interface {
+		Lookup(ctx context.Context, id string) (Statement, error)
+	}
+
+	// Lookup registry function type
+	Lookup func(ctx context.Context, id string) (Statement, error)
+
+	// Getter represents the subset of a sqlx.DB used by the Table registry
+	//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Getter
+	Getter interface {
+		GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+		Rebind(query string) string
+	}
+
+	// Map an in-memory registry
+	Map map[string]Statement
+
+	tableRow struct {
+		Kind         string         `db:"kind"`
+		SQL          string         `db:"sql"`
+		ParamsSchema sql.NullString `db:"params_schema"`
+	}
+)
+
+// Lookup resolves a statement by id
+func (f Lookup) Lookup(ctx context.Context, id string) (Statement, error) {
+	return f(ctx, id)
+}
+
+// Lookup resolves a statement by id
+func (m Map) Lookup(_ context.Context, id string) (Statement, error) {
+	s, ok := m[id]
+	if !ok {
+		return s, ErrNotFound
+	}
+
+	return s, nil
+}
+
+// FS returns a registry holding every <id>.sql file found in the given file system (e.g. an embed.FS)
+// along with its <id>.json params schema, ids are the slash separated file paths without the extension.
+// Statements are loaded upfront, so missing or invalid files are reported right away
+func FS(fsys fs.FS) (Map, error) {
+	m := Map{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || path.Ext(p) != ".sql" {
+			return nil
+		}
+
+		id := strings.TrimSuffix(p, ".sql")
+		q, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return fmt.Errorf("could not read statement %s: %w", id, err)
+		}
+
+		ps, err := fs.ReadFile(fsys, id+".json")
+		if err != nil {
+			return fmt.Errorf("could not read params schema for statement %s: %w", id, err)
+		}
+
+		if !json.Valid(ps) {
+			return fmt.Errorf("could not read params schema for statement %s: invalid json", id)
+		}
+
+		m[id] = Statement{
+			Kind:         KindOf(string(q)),
+			SQL:          string(q),
+			ParamsSchema: ps,
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("could not load statements: %w", err)
+	}
+
+	return m, nil
+}
+
+// Dir returns a registry holding the statements found in the given directory, see FS
+func Dir(dir string) (Map, error) {
+	return FS(os.DirFS(dir))
+}
+
+// Table returns a registry backed by a database table with the id, kind, sql and params_schema columns,
+// statements are looked up on every call so they can be changed without restarting the server
+func Table(db Getter, table string) (Lookup, error) {
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	q := db.Rebind("select kind, sql, params_schema from " + table + " where id = ?")
+	return func(ctx context.Context, id string) (Statement, error) {
+		var r tableRow
+		err := db.GetContext(ctx, &r, q, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return Statement{}, ErrNotFound
+		}
+
+		if err != nil {
+			return Statement{}, fmt.Errorf("could not read statement %s: %w", id, err)
+		}
+
+		s := Statement{Kind: Kind(r.Kind), SQL: r.SQL}
+		if s.Kind == "" {
+			s.Kind = KindOf(r.SQL)
+		}
+
+		if r.ParamsSchema.Valid {
+			s.ParamsSchema = json.RawMessage(r.ParamsSchema.String)
+		}
+
+		return s, nil
+	}, nil
+}
+
+// KindOf infers the kind of a statement from its leading keyword, statements starting with
+// select, values, show, describe or explain are queries, every other statement is an exec. Statements starting
+// with with are queries unless they insert, update, delete or merge, in their CTEs or in their main statement
+func KindOf(q string) Kind {
+	q = strings.TrimLeft(q, " \t\r\n(")
+	for strings.HasPrefix(q, "--") || strings.HasPrefix(q, "/*") {
+		end, skip := "\n", 1
+		if strings.HasPrefix(q, "/*") {
+			end, skip = "*/", 2
+		}
+
+		i := strings.Index(q, end)
+		if i < 0 {
+			return Exec
+		}
+		q = strings.TrimLeft(q[i+skip:], " \t\r\n(")
+	}
+
+	f := strings.Fields(q)
+	if len(f) == 0 {
+		return Exec
+	}
+
+	switch strings.ToLower(strings.TrimRight(f[0], "(;")) {
+	case "select", "values", "show", "describe", "explain":
+		return Query
+	case "with":
+		return cteKind(q)
+	default:
+		return Exec
+	}
+}
+
+// cteKind returns the kind of a statement starting with a CTE list, select ... for update locking clauses
+// aside any insert, update, delete or merge keyword makes it an exec
+func cteKind(q string) Kind {
+	ts, err := lint.Tokenize(q)
+	if err != nil {
+		return Exec
+	}
+
+	for i, t := range ts {
+		switch {
+		case t.Is("insert") || t.Is("delete") || t.Is("merge"):
+			return Exec
+		case t.Is("update") && (i == 0 || !(ts[i-1].Is("for") || ts[i-1].Is("key"))):
+			return Exec
+		}
+	}
+
+	return Query
+}
@@ -0,0 +1,194 @@
+package statement_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing/fstest"
+
+	"github.com/at-silva/ddapi/statement"
+	"github.com/at-silva/ddapi/statement/statementfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry", func() {
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Describe("FS", func() {
+
+		var fsys fstest.MapFS
+
+		BeforeEach(func() {
+			fsys = fstest.MapFS{
+				"products/list.sql":    {Data: []byte("select * from product where name like :name")},
+				"products/list.json":   {Data: []byte(`{"type":"object"}`)},
+				"products/insert.sql":  {Data: []byte("insert into product(name) values(:name)")},
+				"products/insert.json": {Data: []byte(`{"type":"object","required":["name"]}`)},
+				"README.md":            {Data: []byte("not a statement")},
+			}
+		})
+
+		It("should load every statement along with its params schema", func() {
+			r, err := statement.FS(fsys)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(r).Should(HaveLen(2))
+
+			s, err := r.Lookup(ctx, "products/list")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(s).Should(Equal(statement.Statement{
+				Kind:         statement.Query,
+				SQL:          "select * from product where name like :name",
+				ParamsSchema: json.RawMessage(`{"type":"object"}`),
+			}))
+
+			s, err = r.Lookup(ctx, "products/insert")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(s.Kind).Should(Equal(statement.Exec))
+		})
+
+		It("should return ErrNotFound when the statement is not registered", func() {
+			r, err := statement.FS(fsys)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, err = r.Lookup(ctx, "products/delete")
+			Expect(err).Should(Equal(statement.ErrNotFound))
+		})
+
+		It("should fail if a statement has no params schema", func() {
+			delete(fsys, "products/list.json")
+			_, err := statement.FS(fsys)
+			Expect(err).Should(MatchError(ContainSubstring("could not read params schema for statement products/list")))
+		})
+
+		It("should fail if a params schema is not valid json", func() {
+			fsys["products/list.json"] = &fstest.MapFile{Data: []byte("{")}
+			_, err := statement.FS(fsys)
+			Expect(err).Should(MatchError("could not load statements: could not read params schema for statement products/list: invalid json"))
+		})
+	})
+
+	Describe("Dir", func() {
+		It("should load the statements found in a directory", func() {
+			dir, err := ioutil.TempDir("", "ddapi-statement")
+			Expect(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			Expect(ioutil.WriteFile(filepath.Join(dir, "count.sql"), []byte("select count(*) from product"), 0600)).Should(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "count.json"), []byte(`{}`), 0600)).Should(Succeed())
+
+			r, err := statement.Dir(dir)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			s, err := r.Lookup(ctx, "count")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(s.SQL).Should(Equal("select count(*) from product"))
+		})
+
+		It("should fail if the directory does not exist", func() {
+			_, err := statement.Dir("/does/not/exist")
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("Table", func() {
+
+		var fakeGetter *statementfakes.FakeGetter
+
+		BeforeEach(func() {
+			fakeGetter = new(statementfakes.FakeGetter)
+			fakeGetter.RebindStub = func(q string) string { return q }
+		})
+
+		It("should read the statement from the given table", func() {
+			fakeGetter.GetContextStub = func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+				Expect(json.Unmarshal([]byte(`{"Kind":"exec","SQL":"delete from product where id = :id","ParamsSchema":{"String":"{}","Valid":true}}`), dest)).Should(Succeed())
+				return nil
+			}
+
+			r, err := statement.Table(fakeGetter, "ddapi.statements")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			s, err := r.Lookup(ctx, "products/delete")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(s).Should(Equal(statement.Statement{
+				Kind:         statement.Exec,
+				SQL:          "delete from product where id = :id",
+				ParamsSchema: json.RawMessage(`{}`),
+			}))
+
+			_, _, q, args := fakeGetter.GetContextArgsForCall(0)
+			Expect(q).Should(Equal("select kind, sql, params_schema from ddapi.statements where id = ?"))
+			Expect(args).Should(Equal([]interface{}{"products/delete"}))
+		})
+
+		It("should infer the kind when the kind column is empty", func() {
+			fakeGetter.GetContextStub = func(_ context.Context, dest interface{}, _ string, _ ...interface{}) error {
+				Expect(json.Unmarshal([]byte(`{"SQL":"select * from product"}`), dest)).Should(Succeed())
+				return nil
+			}
+
+			r, err := statement.Table(fakeGetter, "statements")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			s, err := r.Lookup(ctx, "products/list")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(s.Kind).Should(Equal(statement.Query))
+		})
+
+		It("should return ErrNotFound when there is no such row", func() {
+			fakeGetter.GetContextReturns(sql.ErrNoRows)
+
+			r, err := statement.Table(fakeGetter, "statements")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, err = r.Lookup(ctx, "products/list")
+			Expect(err).Should(Equal(statement.ErrNotFound))
+		})
+
+		It("should fail if the database call fails", func() {
+			fakeGetter.GetContextReturns(errors.New("connection refused"))
+
+			r, err := statement.Table(fakeGetter, "statements")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, err = r.Lookup(ctx, "products/list")
+			Expect(err).Should(MatchError("could not read statement products/list: connection refused"))
+		})
+
+		It("should reject invalid table names", func() {
+			_, err := statement.Table(fakeGetter, "statements; drop table product")
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("KindOf", func() {
+		It("should infer the statement kind from its leading keyword", func() {
+			Expect(statement.KindOf("select 1")).Should(Equal(statement.Query))
+			Expect(statement.KindOf("  (SELECT 1) union (select 2)")).Should(Equal(statement.Query))
+			Expect(statement.KindOf("with t as (select 1) select * from t")).Should(Equal(statement.Query))
+			Expect(statement.KindOf("with t as (select * from product) select * from t for update")).Should(Equal(statement.Query))
+			Expect(statement.KindOf("with t as (select 'delete' as \"insert\") select * from t")).Should(Equal(statement.Query))
+			Expect(statement.KindOf("-- list products\n/* paged */ select * from product")).Should(Equal(statement.Query))
+			Expect(statement.KindOf("insert into product(name) values(:name)")).Should(Equal(statement.Exec))
+			Expect(statement.KindOf("update product set name = :name")).Should(Equal(statement.Exec))
+			Expect(statement.KindOf("with x as (select 1) delete from t where id = 1")).Should(Equal(statement.Exec))
+			Expect(statement.KindOf("WITH d AS (DELETE FROM product WHERE id = :id RETURNING *) SELECT * FROM d")).Should(Equal(statement.Exec))
+			Expect(statement.KindOf("with recursive t(n) as (select 1) insert into product(id) select n from t")).Should(Equal(statement.Exec))
+			Expect(statement.KindOf("with t as (select 1 as id) update product set name = :name from t where product.id = t.id")).Should(Equal(statement.Exec))
+			Expect(statement.KindOf("-- unterminated comment")).Should(Equal(statement.Exec))
+			Expect(statement.KindOf("")).Should(Equal(statement.Exec))
+		})
+	})
+
+})
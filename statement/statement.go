@@ -0,0 +1,58 @@
+//Package statement contains the DDAPI signed statement envelope
+package statement
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Statement kinds
+const (
+	Query Kind = "query"
+	Exec  Kind = "exec"
+)
+
+type (
+	// Kind the kind of a statement, either Query or Exec
+	Kind string
+
+	// Statement binds the SQL, its params schema, kind and metadata so they can be covered by a single signature
+	Statement struct {
+		Kind         Kind                   `json:"kind"`
+		SQL          string                 `json:"sql"`
+		ParamsSchema json.RawMessage        `json:"paramsSchema"`
+		Meta         map[string]interface{} `json:"meta,omitempty"`
+	}
+)
+
+// Encode encodes a statement into its signable (base64url) form
+func Encode(s Statement) (string, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal statement: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Decode decodes a statement encoded with Encode
+func Decode(e string) (Statement, error) {
+	var s Statement
+
+	b, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return s, fmt.Errorf("could not decode statement: %w", err)
+	}
+
+	err = json.Unmarshal(b, &s)
+	if err != nil {
+		return s, fmt.Errorf("could not unmarshal statement: %w", err)
+	}
+
+	if s.SQL == "" {
+		return s, fmt.Errorf("statement sql cannot be empty")
+	}
+
+	return s, nil
+}
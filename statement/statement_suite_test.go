@@ -0,0 +1,13 @@
+package statement_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestStatement(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Statement Suite")
+}
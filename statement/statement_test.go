@@ -0,0 +1,63 @@
+package statement_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/at-silva/ddapi/statement"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Statement", func() {
+
+	var s statement.Statement
+
+	BeforeEach(func() {
+		s = statement.Statement{
+			Kind:         statement.Exec,
+			SQL:          "insert into product(name) values(:name)",
+			ParamsSchema: json.RawMessage(`{"type":"object","required":["name"],"properties":{"name":{"type":"string"}}}`),
+			Meta:         map[string]interface{}{"screen": "products"},
+		}
+	})
+
+	It("should decode an encoded statement", func() {
+		e, err := statement.Encode(s)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		d, err := statement.Decode(e)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(d).Should(Equal(s))
+	})
+
+	It("should be url safe", func() {
+		s.SQL = "select * from product where name like '%>>>???%'"
+		e, err := statement.Encode(s)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(e).ShouldNot(ContainSubstring("+"))
+		Expect(e).ShouldNot(ContainSubstring("/"))
+		Expect(e).ShouldNot(ContainSubstring("="))
+	})
+
+	It("should fail if the statement is not base64url encoded", func() {
+		_, err := statement.Decode("not base64!")
+		Expect(err).Should(MatchError(ContainSubstring("could not decode statement")))
+	})
+
+	It("should fail if the statement is not valid json", func() {
+		_, err := statement.Decode(base64.RawURLEncoding.EncodeToString([]byte("not json")))
+		Expect(err).Should(MatchError(ContainSubstring("could not unmarshal statement")))
+	})
+
+	It("should fail if the statement sql is empty", func() {
+		s.SQL = ""
+		e, err := statement.Encode(s)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, err = statement.Decode(e)
+		Expect(err).Should(MatchError("statement sql cannot be empty"))
+	})
+
+})